@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/dogab/vitalstack/api/internal/conf"
+	"github.com/dogab/vitalstack/api/pkg/eval"
+	"github.com/dogab/vitalstack/api/pkg/service"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Evaluate the food scan against a labeled dataset",
+	Long: "Run the images of a labeled dataset through the food scan flow (or replay recorded responses) " +
+		"and report the macro errors, the non-food detection and the ingredient matching",
+	RunE: evalEntryPoint,
+}
+
+func evalEntryPoint(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	datasetDir := viper.GetString(conf.EvalDatasetArg)
+	if datasetDir == "" {
+		return fmt.Errorf("no dataset configured, set --%s", conf.EvalDatasetArg)
+	}
+	cases, err := eval.LoadDataset(datasetDir)
+	if err != nil {
+		return err
+	}
+
+	var scanner eval.Scanner
+	if responsesDir := viper.GetString(conf.EvalResponsesArg); responsesDir != "" {
+		slog.Info("replaying recorded responses", "dir", responsesDir)
+		scanner = eval.NewRecordedScanner(responsesDir)
+	} else {
+		// The evaluation always calls the model, so neither the cache nor the mock scan are used
+		svc, err := nutritionServiceFromFlags(ctx, nil, service.FixtureModeOff)
+		if err != nil {
+			return err
+		}
+		if err := svc.ModelUnavailable(); err != nil {
+			return fmt.Errorf("failed to initialize AI provider: %w", err)
+		}
+		scanner = eval.NewServiceScanner(svc)
+	}
+
+	slog.Info("evaluating food scan", "dataset", datasetDir, "cases", len(cases))
+	report := eval.Run(ctx, scanner, cases, viper.GetInt(conf.EvalConcurrencyArg))
+
+	if output := viper.GetString(conf.EvalOutputArg); output != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode evaluation report: %w", err)
+		}
+		//nolint:gosec // the report contains no secrets
+		if err := os.WriteFile(output, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write evaluation report: %w", err)
+		}
+		slog.Info("wrote evaluation report", "path", output)
+	}
+
+	return report.WriteTable(cmd.OutOrStdout())
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/dogab/vitalstack/api/internal/conf"
+	"github.com/dogab/vitalstack/api/internal/repository"
+	"github.com/dogab/vitalstack/api/pkg/off"
+	"github.com/dogab/vitalstack/api/pkg/service"
+	"github.com/dogab/vitalstack/api/pkg/usda"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/supabase-community/supabase-go"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import reference food data into the food database",
+}
+
+var importUSDACmd = &cobra.Command{
+	Use:   "usda <file>",
+	Short: "Import a USDA FoodData Central download",
+	Long: "Import the foods of a FoodData Central JSON file or unzipped CSV download (directory with food.csv " +
+		"and food_nutrient.csv) into the food database. Foods imported before are updated.",
+	Args: cobra.ExactArgs(1),
+	RunE: importUSDAEntryPoint,
+}
+
+var importOFFCmd = &cobra.Command{
+	Use:   "off <file>",
+	Short: "Import an Open Food Facts product dump",
+	Long: "Import the packaged products of an Open Food Facts JSONL dump (openfoodfacts-products.jsonl, optionally " +
+		"gzipped) into the food database, so that they can be looked up by barcode. Products imported before are updated.",
+	Args: cobra.ExactArgs(1),
+	RunE: importOFFEntryPoint,
+}
+
+func importUSDAEntryPoint(cmd *cobra.Command, args []string) error {
+	svc, err := importFoodServiceFromFlags()
+	if err != nil {
+		return err
+	}
+
+	imported, err := importFoods(cmd.Context(), svc, usdaReader(args[0]))
+	if err != nil {
+		return err
+	}
+	slog.Info("imported USDA foods", "path", args[0], "foods", imported)
+	return nil
+}
+
+func importOFFEntryPoint(cmd *cobra.Command, args []string) error {
+	svc, err := importFoodServiceFromFlags()
+	if err != nil {
+		return err
+	}
+
+	imported, err := importFoods(cmd.Context(), svc, offReader(args[0]))
+	if err != nil {
+		return err
+	}
+	slog.Info("imported Open Food Facts products", "path", args[0], "products", imported)
+	return nil
+}
+
+// importFoodServiceFromFlags creates the food service writing to the configured database
+func importFoodServiceFromFlags() (*service.FoodService, error) {
+	supabaseURL := viper.GetString(conf.SupabaseURLArg)
+	if supabaseURL == "" {
+		return nil, fmt.Errorf("no database configured, set --%s", conf.SupabaseURLArg)
+	}
+	supabaseClient, err := supabase.NewClient(supabaseURL, viper.GetString(conf.SupabaseServiceKeyArg), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Supabase client: %w", err)
+	}
+	return service.NewFoodService(repository.NewFoodRepository(supabaseClient)), nil
+}
+
+// foodReader reads the foods of a download and calls fn for every food
+type foodReader func(fn func(food *service.Food) error) error
+
+// usdaReader reads the FoodData Central download at path
+func usdaReader(path string) foodReader {
+	return func(fn func(food *service.Food) error) error { return usda.Read(path, fn) }
+}
+
+// offReader reads the Open Food Facts dump at path
+func offReader(path string) foodReader {
+	return func(fn func(food *service.Food) error) error { return off.Read(path, fn) }
+}
+
+// importFoods imports the foods of the download in batches. Downloads can contain a food more than
+// once (e.g. barcodes normalizing to the same code), the last one wins.
+func importFoods(ctx context.Context, svc *service.FoodService, read foodReader) (int, error) {
+	batchSize := max(viper.GetInt(conf.ImportBatchSizeArg), 1)
+
+	imported := 0
+	batch := make([]service.Food, 0, batchSize)
+	// A batch must not upsert the same food twice, the database rejects the whole batch
+	positions := make(map[string]int, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := svc.ImportFoods(ctx, batch); err != nil {
+			return err
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		clear(positions)
+		slog.Debug("imported foods", "foods", imported)
+		return nil
+	}
+
+	err := read(func(food *service.Food) error {
+		key := string(food.Source) + "/" + food.SourceID
+		if i, ok := positions[key]; ok {
+			batch[i] = *food
+			return nil
+		}
+		positions[key] = len(batch)
+		batch = append(batch, *food)
+		if len(batch) < batchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	return imported, err
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"github.com/dogab/vitalstack/api/internal/conf"
 	"github.com/dogab/vitalstack/api/internal/controller"
+	"github.com/dogab/vitalstack/api/internal/repository"
 	"github.com/dogab/vitalstack/api/internal/server"
+	"github.com/dogab/vitalstack/api/pkg/service"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -16,11 +18,21 @@ var openAPICmd = &cobra.Command{
 }
 
 func openapiEntryPoint(cmd *cobra.Command, _ []string) error {
-	nutritionController := controller.NewNutritionMockController()
+	// The spec only depends on the registered operations, so the mock service avoids AI and database setup
+	foodRepo := repository.NewMemoryFoodRepository()
+	svc, err := mockNutritionServiceFromFlags(cmd.Context(), foodRepo, service.FixtureModeOff)
+	if err != nil {
+		return err
+	}
+	profileSvc := service.NewProfileService(repository.NewMemoryUserRepository())
+	nutritionController := controller.NewNutritionController(svc, profileSvc)
+	foodController := controller.NewFoodController(service.NewFoodService(foodRepo, service.WithFoodLogger(svc)), profileSvc)
+	profileController := controller.NewProfileController(profileSvc)
+	healthController := controller.NewHealthController(service.NewHealthService())
 	api, _ := server.NewServer(":8080")
 
 	// register API endpoints
-	api.RegisterAPI(nutritionController)
+	api.RegisterAPI(nutritionController, foodController, profileController, healthController)
 
 	return api.OpenAPI(viper.GetString(conf.OpenAPIPathArg), server.SpecFormat(viper.GetString(conf.OpenAPIFormatArg)))
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/dogab/vitalstack/api/internal/conf"
+	"github.com/dogab/vitalstack/api/internal/logging"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
@@ -52,6 +53,10 @@ func Execute() (err error) {
 
 	// Add subcommands
 	rootCmd.AddCommand(openAPICmd)
+	rootCmd.AddCommand(evalCmd)
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importUSDACmd)
+	importCmd.AddCommand(importOFFCmd)
 
 	// Generate markdown documentation
 	if len(os.Args) > 1 && os.Args[1] == "gendoc" {
@@ -80,7 +85,7 @@ func initConfig(path string) error {
 }
 
 func initLogger() {
-	var logger *slog.Logger
+	var handler slog.Handler
 	opts := &slog.HandlerOptions{
 		AddSource:   true,
 		ReplaceAttr: replaceAttr,
@@ -90,14 +95,15 @@ func initLogger() {
 
 	switch logEncoding := viper.GetString(conf.LoggingEncodingArg); logEncoding {
 	case "json":
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, opts))
+		handler = slog.NewJSONHandler(os.Stdout, opts)
 	case "logfmt":
-		logger = slog.New(slog.NewTextHandler(os.Stdout, opts))
+		handler = slog.NewTextHandler(os.Stdout, opts)
 	default:
 		slog.Info("unsupported log encoding, using logfmt", "encoding", logEncoding)
-		logger = slog.New(slog.NewTextHandler(os.Stdout, opts))
+		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
-	slog.SetDefault(logger)
+	// Records logged with a request context carry the request ID
+	slog.SetDefault(slog.New(logging.NewContextHandler(handler)))
 }
 
 func setLogLevel(level string, opts *slog.HandlerOptions) {
@@ -2,21 +2,31 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/dogab/vitalstack/api/internal/aiprovider"
 	"github.com/dogab/vitalstack/api/internal/conf"
 	"github.com/dogab/vitalstack/api/internal/controller"
 	"github.com/dogab/vitalstack/api/internal/repository"
 	"github.com/dogab/vitalstack/api/internal/server"
+	"github.com/dogab/vitalstack/api/internal/tracing"
+	"github.com/dogab/vitalstack/api/pkg/cache"
+	"github.com/dogab/vitalstack/api/pkg/off"
 	"github.com/dogab/vitalstack/api/pkg/service"
+	"github.com/dogab/vitalstack/api/prompts"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/supabase-community/supabase-go"
 
-	"github.com/firebase/genkit/go/genkit"
-	"github.com/firebase/genkit/go/plugins/googlegenai"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -34,37 +44,127 @@ func ServerEntryPoint(cmd *cobra.Command, _ []string) error {
 	allowedOrigins := viper.GetStringSlice(conf.ServerOriginArg)
 	devMode := viper.GetBool(conf.DevModeEnabledArg)
 
-	api, shutdown := server.NewServer(
-		serverAddr,
+	// Tracing is initialized first, so that Genkit uses the tracer provider of the configured exporter
+	tracingCfg := tracingConfigFromFlags()
+	shutdownTracing, err := tracing.Init(ctx, tracingCfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Warn("Failed to flush traces", "error", err)
+		}
+	}()
+
+	serverOpts := []server.Option{
 		server.WithAllowedOrigins(allowedOrigins),
 		server.WithDevMode(devMode),
-	)
+		server.WithTracing(tracingCfg.Exporter != tracing.ExporterNone),
+		server.WithMaxBodyBytes(viper.GetInt64(conf.ServerMaxBodyBytesArg)),
+		server.WithRouteMaxBodyBytes(routeMaxBodyBytes()),
+	}
+
+	// The server, services and repositories register their metrics with the registry if metrics are enabled
+	var metricsRegistry *prometheus.Registry
+	var metricsOpts []service.NutritionServiceOption
+	if viper.GetBool(conf.MetricsEnabledArg) {
+		metricsRegistry = newMetricsRegistry()
+		metricsOpts = append(metricsOpts, service.WithMetrics(metricsRegistry))
+		serverOpts = append(serverOpts, server.WithMetrics(metricsRegistry, viper.GetString(conf.MetricsPathArg)))
+	}
+
+	api, shutdown := server.NewServer(serverAddr, serverOpts...)
 
 	supabaseClient, err := supabase.NewClient(viper.GetString(conf.SupabaseURLArg), viper.GetString(conf.SupabaseServiceKeyArg), nil)
 	if err != nil {
 		slog.Warn("Failed to initialize Supabase client", "error", err)
 	}
 	foodLogRepo := repository.NewFoodLogRepository(supabaseClient)
+	foodRepo := repository.NewFoodRepository(supabaseClient)
+	userRepo := repository.NewUserRepository(supabaseClient)
+	// The database calls are traced and, if metrics are enabled, measured
+	var repoMetrics *repository.Metrics
+	if metricsRegistry != nil {
+		repoMetrics = repository.NewMetrics(metricsRegistry)
+	}
+	foodLogRepo = repository.InstrumentFoodLogRepository(foodLogRepo, repoMetrics)
+	foodRepo = repository.InstrumentFoodRepository(foodRepo, repoMetrics)
+	userRepo = repository.InstrumentUserRepository(userRepo, repoMetrics)
+
+	// The readiness probe checks the dependencies of the configured services
+	healthOpts := []service.HealthServiceOption{service.WithHealthCheckTimeout(viper.GetDuration(conf.HealthCheckTimeoutArg))}
+
+	fixtureMode, err := service.ParseFixtureMode(viper.GetString(conf.DevFixturesModeArg))
+	if err != nil {
+		return err
+	}
 
-	var ctrl server.Controller
+	var ctrl, foodCtrl, profileCtrl server.Controller
 	// Register nutrition controller (mock or real based on config)
 	if viper.GetBool(conf.DevModeEnabledArg) && viper.GetBool(conf.DevMocksNutritionServiceArg) {
-		slog.Info("🧪 Using MOCK nutrition controller")
-		ctrl = controller.NewNutritionMockController()
+		slog.Info("🧪 Using MOCK nutrition service with in-memory food logs and profiles")
+		mockFoodRepo, err := mockFoodRepositoryFromFlags(serverShutdownContext)
+		if err != nil {
+			return err
+		}
+		svc, err := mockNutritionServiceFromFlags(serverShutdownContext, mockFoodRepo, fixtureMode, metricsOpts...)
+		if err != nil {
+			return err
+		}
+		profileSvc := service.NewProfileService(repository.NewMemoryUserRepository())
+		ctrl = controller.NewNutritionController(svc, profileSvc)
+		foodCtrl = controller.NewFoodController(service.NewFoodService(mockFoodRepo, foodServiceOptionsFromFlags(svc)...), profileSvc)
+		profileCtrl = controller.NewProfileController(profileSvc)
 	} else {
-		// Initialize Genkit
-		g := genkit.Init(serverShutdownContext,
-			genkit.WithPlugins(&googlegenai.GoogleAI{}),
-			genkit.WithDefaultModel("googleai/gemini-2.5-flash"),
-		)
-
 		mockScan := viper.GetBool(conf.DevMocksScanFoodArg)
-		svc := service.NewNutritionService(g, foodLogRepo, service.WithMockScan(mockScan))
-		ctrl = controller.NewNutritionController(svc)
+		healthOpts = append(healthOpts, service.WithHealthCheck("database", repository.NewHealthRepository(supabaseClient).Ping))
+		opts := []service.NutritionServiceOption{
+			service.WithMockScan(mockScan),
+			service.WithFoodRepository(foodRepo),
+		}
+		if mockScan {
+			mockScanner, err := mockScannerFromFlags()
+			if err != nil {
+				return err
+			}
+			opts = append(opts, service.WithMockScanner(mockScanner))
+		}
+		// Mock responses are not cached, so that repeated scans of an image walk through the responses
+		if viper.GetBool(conf.ScanCacheEnabledArg) && !mockScan {
+			ttl := viper.GetDuration(conf.ScanCacheTTLArg)
+			scanCache := cache.NewLRU(viper.GetInt(conf.ScanCacheSizeArg), ttl)
+			opts = append(opts, service.WithScanCache(scanCache, ttl))
+			healthOpts = append(healthOpts, service.WithHealthCheck("scan_cache", service.CacheHealthCheck(scanCache)))
+		}
+		if viper.GetBool(conf.ScanGroundingEnabledArg) {
+			opts = append(opts, service.WithFoodGrounding(foodRepo, viper.GetFloat64(conf.ScanGroundingMinScoreArg)))
+		}
+		svc, err := nutritionServiceFromFlags(serverShutdownContext, foodLogRepo, fixtureMode, append(opts, metricsOpts...)...)
+		if err != nil {
+			return err
+		}
+		// The provider is only needed if scans call the model
+		if !mockScan && fixtureMode != service.FixtureModeReplay {
+			aiConfig := aiConfigFromFlags()
+			healthOpts = append(healthOpts, service.WithHealthCheck("ai_provider", func(ctx context.Context) error {
+				if err := svc.ModelUnavailable(); err != nil {
+					return err
+				}
+				return aiConfig.Ping(ctx)
+			}))
+		}
+		profileSvc := service.NewProfileService(userRepo)
+		ctrl = controller.NewNutritionController(svc, profileSvc)
+		foodCtrl = controller.NewFoodController(service.NewFoodService(foodRepo, foodServiceOptionsFromFlags(svc)...), profileSvc)
+		profileCtrl = controller.NewProfileController(profileSvc)
 	}
 
+	healthCtrl := controller.NewHealthController(service.NewHealthService(healthOpts...))
+
 	// register the endpoints of the controllers
-	api.RegisterAPI(ctrl)
+	api.RegisterAPI(ctrl, foodCtrl, profileCtrl, healthCtrl)
 
 	// start the server
 	err = api.Serve(ctx)
@@ -88,3 +188,206 @@ func ServerEntryPoint(cmd *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// newMetricsRegistry creates the registry of the Prometheus metrics with the Go runtime and process metrics
+func newMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// routeMaxBodyBytes reads the per route body limits, which may be set by flag or config file
+func routeMaxBodyBytes() map[string]int64 {
+	limits := map[string]int64{}
+	for operationID, limit := range viper.GetStringMap(conf.ServerRouteMaxBodyBytesArg) {
+		bytes, err := cast.ToInt64E(limit)
+		if err != nil {
+			slog.Warn("ignoring invalid route body limit", "operation_id", operationID, "limit", limit)
+			continue
+		}
+		limits[operationID] = bytes
+	}
+	return limits
+}
+
+// nutritionServiceFromFlags initializes the configured AI provider and prompts and creates the
+// nutrition service. Replaying fixtures never calls the model, so the AI provider is not initialized
+// and needs no credentials. If the provider cannot be initialized, e.g. without an API key, the service
+// is created with the model marked unavailable (see NutritionService.ModelUnavailable) instead of failing,
+// so that the server starts and reports the provider as down. The options are applied after the configured ones.
+func nutritionServiceFromFlags(ctx context.Context, repo repository.FoodLogRepository, fixtureMode service.FixtureMode, opts ...service.NutritionServiceOption) (*service.NutritionService, error) {
+	aiConfig := aiConfigFromFlags()
+	var g *genkit.Genkit
+	var modelUnavailable error
+	if fixtureMode == service.FixtureModeReplay {
+		slog.Info("Replaying scan fixtures, the AI provider is not initialized", "dir", viper.GetString(conf.DevFixturesDirArg))
+		modelUnavailable = errors.New("the AI provider is not initialized while replaying fixtures")
+	} else {
+		// Initialize Genkit with the configured AI provider
+		var err error
+		if g, err = aiprovider.Init(ctx, aiConfig); err != nil {
+			slog.Error("AI provider is unavailable, scans calling the model are answered with 503", "error", err)
+			modelUnavailable = err
+		}
+	}
+	if g == nil {
+		g = genkit.Init(ctx)
+	}
+
+	scanPrompts, err := scanPromptsFromFlags(g)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	labelPrompt, err := labelPromptFromFlags(g)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	opts = append([]service.NutritionServiceOption{
+		service.WithScanPrompts(scanPrompts...),
+		service.WithLabelPrompt(labelPrompt),
+		service.WithImageLimits(viper.GetInt64(conf.ScanMaxImageBytesArg), viper.GetInt(conf.ScanMaxImageDimensionArg)),
+		service.WithGenerationConfig(aiConfig.GenerationConfig()),
+		service.WithModels(aiConfig.Models()...),
+		service.WithRetryPolicy(service.RetryPolicy{
+			MaxAttempts:    viper.GetInt(conf.ScanRetryMaxAttemptsArg),
+			InitialBackoff: viper.GetDuration(conf.ScanRetryInitialBackoffArg),
+			MaxBackoff:     viper.GetDuration(conf.ScanRetryMaxBackoffArg),
+			Multiplier:     viper.GetFloat64(conf.ScanRetryMultiplierArg),
+			Jitter:         viper.GetFloat64(conf.ScanRetryJitterArg),
+		}),
+		service.WithScanTimeout(viper.GetDuration(conf.ScanTimeoutArg)),
+		service.WithClarificationThreshold(viper.GetFloat64(conf.ScanClarificationThresholdArg)),
+		service.WithFixtures(fixtureMode, viper.GetString(conf.DevFixturesDirArg)),
+		service.WithModelUnavailable(modelUnavailable),
+	}, opts...)
+
+	return service.NewNutritionService(g, repo, opts...), nil
+}
+
+// mockNutritionServiceFromFlags creates a nutrition service which answers scans with the configured
+// mock responses and keeps the food logs in memory, so that neither an AI provider nor a database is needed.
+// Nutrition labels are stored in foodRepo, the scanned ingredients are matched against it if grounding is enabled.
+// Scans are answered from the recorded fixtures in replay mode, mock results are never recorded.
+// The options are applied after the configured ones.
+func mockNutritionServiceFromFlags(ctx context.Context, foodRepo repository.FoodRepository, fixtureMode service.FixtureMode, extraOpts ...service.NutritionServiceOption) (*service.NutritionService, error) {
+	mockScanner, err := mockScannerFromFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []service.NutritionServiceOption{
+		service.WithMockScan(true),
+		service.WithMockScanner(mockScanner),
+		service.WithImageLimits(viper.GetInt64(conf.ScanMaxImageBytesArg), viper.GetInt(conf.ScanMaxImageDimensionArg)),
+		service.WithScanTimeout(viper.GetDuration(conf.ScanTimeoutArg)),
+		service.WithClarificationThreshold(viper.GetFloat64(conf.ScanClarificationThresholdArg)),
+		service.WithFoodRepository(foodRepo),
+		service.WithFixtures(fixtureMode, viper.GetString(conf.DevFixturesDirArg)),
+	}
+	if viper.GetBool(conf.ScanGroundingEnabledArg) {
+		opts = append(opts, service.WithFoodGrounding(foodRepo, viper.GetFloat64(conf.ScanGroundingMinScoreArg)))
+	}
+	opts = append(opts, extraOpts...)
+
+	return service.NewNutritionService(genkit.Init(ctx), repository.NewMemoryFoodLogRepository(), opts...), nil
+}
+
+// foodServiceOptionsFromFlags configures the food service to log products with the nutrition service
+// and to look up unknown barcodes if a product API is configured
+func foodServiceOptionsFromFlags(logger service.FoodLogger) []service.FoodServiceOption {
+	opts := []service.FoodServiceOption{service.WithFoodLogger(logger)}
+	if baseURL := viper.GetString(conf.FoodsBarcodeLookupURLArg); baseURL != "" {
+		client := off.NewClient(baseURL, viper.GetDuration(conf.FoodsBarcodeLookupTimeoutArg))
+		opts = append(opts, service.WithProductLookup(client))
+	}
+	return opts
+}
+
+// mockFoodRepositoryFromFlags creates an in-memory food database, which is loaded from the
+// configured FoodData Central download and Open Food Facts dump
+func mockFoodRepositoryFromFlags(ctx context.Context) (repository.FoodRepository, error) {
+	repo := repository.NewMemoryFoodRepository()
+	if path := viper.GetString(conf.DevMocksFoodsFileArg); path != "" {
+		imported, err := importFoods(ctx, service.NewFoodService(repo), usdaReader(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load mock foods: %w", err)
+		}
+		slog.Info("loaded mock foods", "path", path, "foods", imported)
+	}
+	if path := viper.GetString(conf.DevMocksProductsFileArg); path != "" {
+		imported, err := importFoods(ctx, service.NewFoodService(repo), offReader(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load mock products: %w", err)
+		}
+		slog.Info("loaded mock products", "path", path, "products", imported)
+	}
+	return repo, nil
+}
+
+// mockScannerFromFlags creates the mock scanner from the configured responses file and selection
+func mockScannerFromFlags() (*service.MockScanner, error) {
+	var responses []service.MockResponse
+	if path := viper.GetString(conf.DevMocksScanFileArg); path != "" {
+		var err error
+		if responses, err = service.LoadMockResponses(path); err != nil {
+			return nil, err
+		}
+	}
+	return service.NewMockScanner(
+		responses,
+		service.MockSelection(viper.GetString(conf.DevMocksScanSelectionArg)),
+		viper.GetUint64(conf.DevMocksScanSeedArg),
+	)
+}
+
+// scanPromptsFromFlags loads the food scan prompt variants from the configured prompt directory
+// or from the prompts embedded in the binary
+func scanPromptsFromFlags(g *genkit.Genkit) ([]service.ScanPrompt, error) {
+	return service.LoadScanPrompts(g, promptsFSFromFlags(), viper.GetStringSlice(conf.PromptsFoodScanVariantsArg)...)
+}
+
+// labelPromptFromFlags loads the nutrition label prompt from the configured prompt directory. Prompt
+// directories without it, e.g. of food scan variants only, use the prompt embedded in the binary.
+func labelPromptFromFlags(g *genkit.Genkit) (service.ScanPrompt, error) {
+	labelPrompt, err := service.LoadLabelPrompt(g, promptsFSFromFlags())
+	if errors.Is(err, fs.ErrNotExist) {
+		return service.LoadLabelPrompt(g, prompts.FS)
+	}
+	return labelPrompt, err
+}
+
+// promptsFSFromFlags returns the configured prompt directory or the prompts embedded in the binary
+func promptsFSFromFlags() fs.FS {
+	if dir := viper.GetString(conf.PromptsDirArg); dir != "" {
+		return os.DirFS(dir)
+	}
+	return prompts.FS
+}
+
+// tracingConfigFromFlags reads the tracing configuration
+func tracingConfigFromFlags() tracing.Config {
+	return tracing.Config{
+		Exporter:    tracing.Exporter(viper.GetString(conf.TracingExporterArg)),
+		Endpoint:    viper.GetString(conf.TracingOTLPEndpointArg),
+		File:        viper.GetString(conf.TracingFileArg),
+		SampleRatio: viper.GetFloat64(conf.TracingSampleRatioArg),
+	}
+}
+
+// aiConfigFromFlags reads the AI provider configuration
+func aiConfigFromFlags() aiprovider.Config {
+	return aiprovider.Config{
+		Provider:       aiprovider.Provider(viper.GetString(conf.AIProviderArg)),
+		Model:          viper.GetString(conf.AIModelArg),
+		FallbackModels: viper.GetStringSlice(conf.AIFallbackModelsArg),
+		Temperature:    viper.GetFloat64(conf.AITemperatureArg),
+		APIKey:         aiprovider.ResolveAPIKey(viper.GetString(conf.AIAPIKeyArg), viper.GetString(conf.AIAPIKeyEnvArg)),
+		BaseURL:        viper.GetString(conf.AIBaseURLArg),
+		ProjectID:      viper.GetString(conf.AIVertexProjectArg),
+		Location:       viper.GetString(conf.AIVertexLocationArg),
+	}
+}
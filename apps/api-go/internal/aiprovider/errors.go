@@ -0,0 +1,88 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"syscall"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/openai/openai-go"
+	"google.golang.org/genai"
+)
+
+// ollamaStatusPattern extracts the HTTP status from errors of the Ollama plugin, which are not typed
+var ollamaStatusPattern = regexp.MustCompile(`non-200 status: (\d{3})`)
+
+// IsRetryable reports whether err is a transient provider error (rate limits, overload,
+// timeouts of the provider or connection failures) for which a retry may succeed.
+// Cancellation or expiry of the caller's context is never retryable, neither are Genkit
+// INTERNAL errors, which Genkit reports for deterministic failures (e.g. of tools or resources).
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if status, ok := StatusCode(err); ok {
+		return isRetryableStatus(status)
+	}
+
+	var genkitErr *core.GenkitError
+	if errors.As(err, &genkitErr) {
+		switch genkitErr.Status {
+		case core.UNAVAILABLE, core.RESOURCE_EXHAUSTED, core.DEADLINE_EXCEEDED, core.ABORTED:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// StatusCode returns the HTTP status code reported by the provider API, if err carries one
+func StatusCode(err error) (int, bool) {
+	var genaiErr genai.APIError
+	if errors.As(err, &genaiErr) {
+		return genaiErr.Code, true
+	}
+
+	var genaiErrPtr *genai.APIError
+	if errors.As(err, &genaiErrPtr) {
+		return genaiErrPtr.Code, true
+	}
+
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return openaiErr.StatusCode, true
+	}
+
+	if m := ollamaStatusPattern.FindStringSubmatch(err.Error()); m != nil {
+		status, convErr := strconv.Atoi(m[1])
+		return status, convErr == nil
+	}
+
+	return 0, false
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
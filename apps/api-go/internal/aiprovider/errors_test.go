@@ -0,0 +1,60 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/firebase/genkit/go/core"
+	"github.com/openai/openai-go"
+	"google.golang.org/genai"
+)
+
+// timeoutError is a net.Error which timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return false }
+
+var _ net.Error = timeoutError{}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "Gemini rate limit", err: genai.APIError{Code: http.StatusTooManyRequests}, want: true},
+		{name: "Gemini overloaded", err: &genai.APIError{Code: http.StatusServiceUnavailable}, want: true},
+		{name: "Gemini bad request", err: genai.APIError{Code: http.StatusBadRequest}, want: false},
+		{name: "OpenAI server error", err: &openai.Error{StatusCode: http.StatusInternalServerError}, want: true},
+		{name: "OpenAI unauthorized", err: &openai.Error{StatusCode: http.StatusUnauthorized}, want: false},
+		{name: "wrapped status", err: fmt.Errorf("generate: %w", genai.APIError{Code: http.StatusGatewayTimeout}), want: true},
+		{name: "Ollama overloaded", err: errors.New("server returned non-200 status: 503, body: busy"), want: true},
+		{name: "Ollama not found", err: errors.New("server returned non-200 status: 404, body: model not found"), want: false},
+		{name: "Genkit unavailable", err: core.NewError(core.UNAVAILABLE, "model overloaded"), want: true},
+		{name: "Genkit resource exhausted", err: core.NewError(core.RESOURCE_EXHAUSTED, "quota"), want: true},
+		{name: "Genkit internal", err: core.NewError(core.INTERNAL, "tool failed"), want: false},
+		{name: "Genkit invalid argument", err: core.NewError(core.INVALID_ARGUMENT, "bad schema"), want: false},
+		{name: "network timeout", err: &net.OpError{Op: "read", Err: timeoutError{}}, want: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, want: true},
+		{name: "connection reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "caller deadline", err: fmt.Errorf("generate: %w", context.DeadlineExceeded), want: false},
+		{name: "canceled while rate limited", err: errors.Join(genai.APIError{Code: http.StatusTooManyRequests}, context.Canceled), want: false},
+		{name: "other error", err: errors.New("invalid JSON in model response"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
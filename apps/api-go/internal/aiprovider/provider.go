@@ -0,0 +1,263 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/firebase/genkit/go/core/api"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/firebase/genkit/go/plugins/compat_oai"
+	"github.com/firebase/genkit/go/plugins/googlegenai"
+	"github.com/firebase/genkit/go/plugins/ollama"
+	"github.com/openai/openai-go/option"
+)
+
+// Provider identifies the Genkit plugin used to serve the AI models
+type Provider string
+
+const (
+	// ProviderGoogleAI uses the Gemini API of Google AI Studio
+	ProviderGoogleAI Provider = "googleai"
+	// ProviderVertexAI uses Gemini models hosted on Google Cloud Vertex AI
+	ProviderVertexAI Provider = "vertexai"
+	// ProviderOpenAI uses the OpenAI API or any OpenAI compatible endpoint (e.g. a local stub server)
+	ProviderOpenAI Provider = "openai"
+	// ProviderOllama uses models served by a local Ollama instance
+	ProviderOllama Provider = "ollama"
+)
+
+// Providers lists all supported providers
+var Providers = []Provider{ProviderGoogleAI, ProviderVertexAI, ProviderOpenAI, ProviderOllama}
+
+const (
+	// DefaultOllamaAddress is the address of a locally running Ollama server
+	DefaultOllamaAddress = "http://localhost:11434"
+	// DefaultOllamaTimeout is the response timeout for Ollama models in seconds
+	DefaultOllamaTimeout = 120
+)
+
+// Config describes which provider and model the AI flows run against
+type Config struct {
+	Provider Provider
+	// Model is the model name without the provider prefix, e.g. "gemini-2.5-flash"
+	Model string
+	// FallbackModels are tried in order when the primary model fails. They must be served by the same provider.
+	FallbackModels []string
+	// Temperature is the sampling temperature. A negative value uses the provider default.
+	Temperature float64
+	// APIKey is passed to the provider. If empty, the provider specific environment variables are consulted.
+	APIKey string
+	// BaseURL is the endpoint of OpenAI compatible servers or the address of the Ollama server
+	BaseURL string
+	// ProjectID and Location select the Google Cloud project and region for Vertex AI
+	ProjectID string
+	Location  string
+}
+
+// LogValue implements slog.LogValuer for structured logging. The API key is never logged.
+func (c Config) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("provider", string(c.Provider)),
+		slog.String("model", c.Model),
+		slog.Bool("api_key_set", c.APIKey != ""),
+	}
+	if len(c.FallbackModels) > 0 {
+		attrs = append(attrs, slog.Any("fallback_models", c.FallbackModels))
+	}
+	if c.Temperature >= 0 {
+		attrs = append(attrs, slog.Float64("temperature", c.Temperature))
+	}
+	if c.BaseURL != "" {
+		attrs = append(attrs, slog.String("base_url", c.BaseURL))
+	}
+	if c.ProjectID != "" {
+		attrs = append(attrs, slog.String("project_id", c.ProjectID), slog.String("location", c.Location))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// ModelName returns the fully qualified model name as registered in Genkit
+func (c Config) ModelName() string {
+	return QualifyModelName(c.Provider, c.Model)
+}
+
+// Models returns the fully qualified names of the primary model followed by the fallback models
+func (c Config) Models() []string {
+	models := make([]string, 0, len(c.FallbackModels)+1)
+	models = append(models, c.ModelName())
+	for _, model := range c.FallbackModels {
+		models = append(models, QualifyModelName(c.Provider, model))
+	}
+	return models
+}
+
+// QualifyModelName prefixes the model with the provider unless it already carries a prefix
+func QualifyModelName(provider Provider, model string) string {
+	if strings.Contains(model, "/") {
+		return model
+	}
+	return string(provider) + "/" + model
+}
+
+// GenerationConfig returns the model configuration passed with every generate call,
+// or nil if the provider defaults should be used.
+// A map is used since it is understood by all providers, unlike their typed configs.
+func (c Config) GenerationConfig() any {
+	if c.Temperature < 0 {
+		return nil
+	}
+	return map[string]any{"temperature": c.Temperature}
+}
+
+// ResolveAPIKey returns key if set, otherwise the value of the environment variable envName
+func ResolveAPIKey(key, envName string) string {
+	if key != "" || envName == "" {
+		return key
+	}
+	return os.Getenv(envName)
+}
+
+// Validate reports configurations the provider cannot serve requests with, e.g. a missing model or
+// API key. Credentials may also be set by the environment variables the provider plugins read.
+func (c Config) Validate() error {
+	if c.Model == "" {
+		return fmt.Errorf("no AI model configured for provider %q", c.Provider)
+	}
+	switch c.Provider {
+	case ProviderGoogleAI:
+		if c.APIKey == "" && os.Getenv("GEMINI_API_KEY") == "" && os.Getenv("GOOGLE_API_KEY") == "" {
+			return errors.New("no API key configured for Google AI")
+		}
+	case ProviderVertexAI:
+		if c.ProjectID == "" && os.Getenv("GOOGLE_CLOUD_PROJECT") == "" {
+			return errors.New("no Google Cloud project configured for Vertex AI")
+		}
+		if c.Location == "" && os.Getenv("GOOGLE_CLOUD_LOCATION") == "" && os.Getenv("GOOGLE_CLOUD_REGION") == "" {
+			return errors.New("no Google Cloud location configured for Vertex AI")
+		}
+	case ProviderOpenAI:
+		if c.APIKey == "" && os.Getenv("OPENAI_API_KEY") == "" {
+			return errors.New("no API key configured for OpenAI")
+		}
+	case ProviderOllama:
+	default:
+		return fmt.Errorf("unsupported AI provider %q, expected one of %v", c.Provider, Providers)
+	}
+	return nil
+}
+
+// Ping checks that the provider can serve requests. The configuration is validated, and the servers of
+// Ollama and OpenAI compatible endpoints are asked for their models, so that an unreachable server or
+// rejected credentials are reported. The hosted Google and OpenAI APIs are not called.
+func (c Config) Ping(ctx context.Context) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	var url, apiKey string
+	switch {
+	case c.Provider == ProviderOllama:
+		url = strings.TrimSuffix(c.ollamaAddress(), "/") + "/api/tags"
+	case c.Provider == ProviderOpenAI && c.BaseURL != "":
+		url = strings.TrimSuffix(c.BaseURL, "/") + "/models"
+		apiKey = ResolveAPIKey(c.APIKey, "OPENAI_API_KEY")
+	default:
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid AI provider endpoint: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("AI provider endpoint is unreachable: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // the body is not read
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("AI provider endpoint answered %s", resp.Status)
+	}
+	return nil
+}
+
+// ollamaAddress returns the address of the Ollama server
+func (c Config) ollamaAddress() string {
+	if c.BaseURL == "" {
+		return DefaultOllamaAddress
+	}
+	return c.BaseURL
+}
+
+// Init initializes Genkit with the plugin of the configured provider and sets the configured model
+// as the default model. Additional models of the same provider can be registered with DefineModels.
+// Invalid configurations (see Validate) and panics of the provider plugins, e.g. on missing Google Cloud
+// credentials, are returned as error.
+func Init(ctx context.Context, cfg Config, opts ...genkit.GenkitOption) (g *genkit.Genkit, err error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var plugin api.Plugin
+	switch cfg.Provider {
+	case ProviderGoogleAI:
+		plugin = &googlegenai.GoogleAI{APIKey: cfg.APIKey}
+	case ProviderVertexAI:
+		plugin = &googlegenai.VertexAI{ProjectID: cfg.ProjectID, Location: cfg.Location}
+	case ProviderOpenAI:
+		plugin = &compat_oai.OpenAICompatible{
+			Provider: string(ProviderOpenAI),
+			APIKey:   cfg.APIKey,
+			BaseURL:  cfg.BaseURL,
+			// Retries are handled by the service retry policy
+			Opts: []option.RequestOption{option.WithMaxRetries(0)},
+		}
+	case ProviderOllama:
+		plugin = &ollama.Ollama{ServerAddress: cfg.ollamaAddress(), Timeout: DefaultOllamaTimeout}
+	default:
+		return nil, fmt.Errorf("unsupported AI provider %q, expected one of %v", cfg.Provider, Providers)
+	}
+
+	slog.Info("initializing AI provider", "config", cfg)
+
+	defer func() {
+		if r := recover(); r != nil {
+			g, err = nil, fmt.Errorf("failed to initialize AI provider %q: %v", cfg.Provider, r)
+		}
+	}()
+
+	opts = append(opts,
+		genkit.WithPlugins(plugin),
+		genkit.WithDefaultModel(cfg.ModelName()),
+	)
+	g = genkit.Init(ctx, opts...)
+
+	DefineModels(g, plugin, cfg.Models()...)
+
+	return g, nil
+}
+
+// DefineModels registers models which the provider plugin does not resolve on its own.
+// Google AI, Vertex AI and OpenAI compatible plugins resolve models by name, while
+// Ollama models have to be defined explicitly. Images are only sent to Ollama models
+// which the plugin knows to be multimodal (e.g. llava, gemma3).
+func DefineModels(g *genkit.Genkit, plugin api.Plugin, models ...string) {
+	p, ok := plugin.(*ollama.Ollama)
+	if !ok {
+		return
+	}
+	for _, model := range models {
+		name := strings.TrimPrefix(model, plugin.Name()+"/")
+		if !ollama.IsDefinedModel(g, name) {
+			p.DefineModel(g, ollama.ModelDefinition{Name: name, Type: "chat"}, nil)
+		}
+	}
+}
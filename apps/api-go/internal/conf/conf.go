@@ -45,6 +45,305 @@ const (
 	// ServerShutdownTimeoutHelp is the help message for the server shutdown grace period
 	ServerShutdownTimeoutHelp = "Duration to wait for the server to shutdown gracefully"
 
+	// ServerMaxBodyBytesArg is the flag name for the default request body limit
+	ServerMaxBodyBytesArg = serverKey + "max-body-bytes"
+	// ServerMaxBodyBytesDefault is the default request body limit (1MB)
+	ServerMaxBodyBytesDefault = 1 << 20
+	// ServerMaxBodyBytesHelp is the help message for the default request body limit flag
+	ServerMaxBodyBytesHelp = "Maximum request body size in bytes for operations without a route specific limit"
+
+	// ServerRouteMaxBodyBytesArg is the flag name for the per route request body limits
+	ServerRouteMaxBodyBytesArg = serverKey + "route-max-body-bytes"
+	// ServerRouteMaxBodyBytesHelp is the help message for the per route request body limits flag
+	ServerRouteMaxBodyBytesHelp = "Maximum request body size in bytes per operation ID (format: operation-id=bytes)"
+
+	// AI
+	aiKey = "ai."
+	// AIProviderArg is the flag name for the AI model provider
+	AIProviderArg = aiKey + "provider"
+	// AIProviderDefault is the default AI model provider
+	AIProviderDefault = "googleai"
+	// AIProviderHelp is the help message for the AI model provider flag
+	AIProviderHelp = "AI model provider (googleai, vertexai, openai, ollama)"
+
+	// AIModelArg is the flag name for the AI model name
+	AIModelArg = aiKey + "model"
+	// AIModelDefault is the default AI model name
+	AIModelDefault = "gemini-2.5-flash"
+	// AIModelHelp is the help message for the AI model name flag
+	AIModelHelp = "AI model name without the provider prefix"
+
+	// AIFallbackModelsArg is the flag name for the AI fallback models
+	AIFallbackModelsArg = aiKey + "fallback-models"
+	// AIFallbackModelsHelp is the help message for the AI fallback models flag
+	AIFallbackModelsHelp = "Models of the same provider tried in order when the primary model fails, multiple models can be set"
+
+	// AITemperatureArg is the flag name for the AI sampling temperature
+	AITemperatureArg = aiKey + "temperature"
+	// AITemperatureDefault is the default AI sampling temperature (negative uses the provider default)
+	AITemperatureDefault = -1.0
+	// AITemperatureHelp is the help message for the AI sampling temperature flag
+	AITemperatureHelp = "AI sampling temperature, a negative value uses the provider default"
+
+	// AIAPIKeyArg is the flag name for the AI provider API key
+	AIAPIKeyArg = aiKey + "api-key"
+	// AIAPIKeyDefault is the default AI provider API key
+	AIAPIKeyDefault = ""
+	// AIAPIKeyHelp is the help message for the AI provider API key flag
+	AIAPIKeyHelp = "AI provider API key (if empty, ai.api-key-env or the provider specific environment variables are consulted)"
+
+	// AIAPIKeyEnvArg is the flag name for the environment variable holding the AI provider API key
+	AIAPIKeyEnvArg = aiKey + "api-key-env"
+	// AIAPIKeyEnvDefault is the default environment variable holding the AI provider API key
+	AIAPIKeyEnvDefault = ""
+	// AIAPIKeyEnvHelp is the help message for the AI provider API key environment variable flag
+	AIAPIKeyEnvHelp = "Environment variable to read the AI provider API key from (e.g. GEMINI_API_KEY, OPENAI_API_KEY)"
+
+	// AIBaseURLArg is the flag name for the AI provider endpoint
+	AIBaseURLArg = aiKey + "base-url"
+	// AIBaseURLDefault is the default AI provider endpoint
+	AIBaseURLDefault = ""
+	// AIBaseURLHelp is the help message for the AI provider endpoint flag
+	AIBaseURLHelp = "Endpoint of an OpenAI compatible server or address of the Ollama server"
+
+	// AIVertexProjectArg is the flag name for the Vertex AI project
+	AIVertexProjectArg = aiKey + "vertex.project"
+	// AIVertexProjectDefault is the default Vertex AI project
+	AIVertexProjectDefault = ""
+	// AIVertexProjectHelp is the help message for the Vertex AI project flag
+	AIVertexProjectHelp = "Google Cloud project for Vertex AI (defaults to GOOGLE_CLOUD_PROJECT)"
+
+	// AIVertexLocationArg is the flag name for the Vertex AI location
+	AIVertexLocationArg = aiKey + "vertex.location"
+	// AIVertexLocationDefault is the default Vertex AI location
+	AIVertexLocationDefault = ""
+	// AIVertexLocationHelp is the help message for the Vertex AI location flag
+	AIVertexLocationHelp = "Google Cloud location for Vertex AI (defaults to GOOGLE_CLOUD_LOCATION)"
+
+	// Scan
+	scanKey = "scan."
+	// ScanMaxImageBytesArg is the flag name for the maximum decoded image size
+	ScanMaxImageBytesArg = scanKey + "max-image-bytes"
+	// ScanMaxImageBytesDefault is the default maximum decoded image size (10MB)
+	ScanMaxImageBytesDefault = 10 << 20
+	// ScanMaxImageBytesHelp is the help message for the maximum decoded image size flag
+	ScanMaxImageBytesHelp = "Maximum size in bytes of a decoded scan image (0 disables the check)"
+
+	// ScanMaxImageDimensionArg is the flag name for the maximum image width or height
+	ScanMaxImageDimensionArg = scanKey + "max-image-dimension"
+	// ScanMaxImageDimensionDefault is the default maximum image width or height in pixels
+	ScanMaxImageDimensionDefault = 8192
+	// ScanMaxImageDimensionHelp is the help message for the maximum image dimension flag
+	ScanMaxImageDimensionHelp = "Maximum width or height in pixels of a scan image (0 disables the check)"
+
+	// ScanCacheEnabledArg is the flag name for enabling the scan result cache
+	ScanCacheEnabledArg = scanKey + "cache.enabled"
+	// ScanCacheEnabledDefault is the default value for the scan result cache
+	ScanCacheEnabledDefault = true
+	// ScanCacheEnabledHelp is the help message for the scan result cache flag
+	ScanCacheEnabledHelp = "Return cached results for repeated scans of the same image and description"
+
+	// ScanCacheSizeArg is the flag name for the scan result cache size
+	ScanCacheSizeArg = scanKey + "cache.size"
+	// ScanCacheSizeDefault is the default number of cached scan results
+	ScanCacheSizeDefault = 256
+	// ScanCacheSizeHelp is the help message for the scan result cache size flag
+	ScanCacheSizeHelp = "Maximum number of cached scan results"
+
+	// ScanCacheTTLArg is the flag name for the scan result cache expiry
+	ScanCacheTTLArg = scanKey + "cache.ttl"
+	// ScanCacheTTLDefault is the default scan result cache expiry
+	ScanCacheTTLDefault = time.Hour
+	// ScanCacheTTLHelp is the help message for the scan result cache expiry flag
+	ScanCacheTTLHelp = "Duration after which cached scan results expire"
+
+	// ScanRetryMaxAttemptsArg is the flag name for the number of attempts per model
+	ScanRetryMaxAttemptsArg = scanKey + "retry.max-attempts"
+	// ScanRetryMaxAttemptsDefault is the default number of attempts per model
+	ScanRetryMaxAttemptsDefault = 3
+	// ScanRetryMaxAttemptsHelp is the help message for the number of attempts per model flag
+	ScanRetryMaxAttemptsHelp = "Attempts per model for transient AI errors including the first call (1 disables retries)"
+
+	// ScanRetryInitialBackoffArg is the flag name for the delay before the first retry
+	ScanRetryInitialBackoffArg = scanKey + "retry.initial-backoff"
+	// ScanRetryInitialBackoffDefault is the default delay before the first retry
+	ScanRetryInitialBackoffDefault = 500 * time.Millisecond
+	// ScanRetryInitialBackoffHelp is the help message for the delay before the first retry flag
+	ScanRetryInitialBackoffHelp = "Delay before the first retry of a transient AI error"
+
+	// ScanRetryMaxBackoffArg is the flag name for the maximum delay between retries
+	ScanRetryMaxBackoffArg = scanKey + "retry.max-backoff"
+	// ScanRetryMaxBackoffDefault is the default maximum delay between retries
+	ScanRetryMaxBackoffDefault = 5 * time.Second
+	// ScanRetryMaxBackoffHelp is the help message for the maximum delay between retries flag
+	ScanRetryMaxBackoffHelp = "Maximum delay between retries of transient AI errors"
+
+	// ScanRetryMultiplierArg is the flag name for the backoff multiplier
+	ScanRetryMultiplierArg = scanKey + "retry.multiplier"
+	// ScanRetryMultiplierDefault is the default backoff multiplier
+	ScanRetryMultiplierDefault = 2.0
+	// ScanRetryMultiplierHelp is the help message for the backoff multiplier flag
+	ScanRetryMultiplierHelp = "Factor by which the retry delay grows after every attempt"
+
+	// ScanRetryJitterArg is the flag name for the backoff jitter
+	ScanRetryJitterArg = scanKey + "retry.jitter"
+	// ScanRetryJitterDefault is the default backoff jitter
+	ScanRetryJitterDefault = 0.2
+	// ScanRetryJitterHelp is the help message for the backoff jitter flag
+	ScanRetryJitterHelp = "Fraction (0-1) of the retry delay which is randomized"
+
+	// ScanTimeoutArg is the flag name for the scan timeout
+	ScanTimeoutArg = scanKey + "timeout"
+	// ScanTimeoutDefault is the default scan timeout
+	ScanTimeoutDefault = 60 * time.Second
+	// ScanTimeoutHelp is the help message for the scan timeout flag
+	ScanTimeoutHelp = "Maximum duration of a food scan including retries and fallback models (0 disables the timeout)"
+
+	// ScanClarificationThresholdArg is the flag name for the clarification confidence threshold
+	ScanClarificationThresholdArg = scanKey + "clarification.threshold"
+	// ScanClarificationThresholdDefault is the default clarification confidence threshold
+	ScanClarificationThresholdDefault = 0.6
+	// ScanClarificationThresholdHelp is the help message for the clarification confidence threshold flag
+	ScanClarificationThresholdHelp = "Confidence below which scans return clarifying questions and alternative dishes (0 disables clarification)"
+
+	// ScanGroundingEnabledArg is the flag name for matching scanned ingredients against the food database
+	ScanGroundingEnabledArg = scanKey + "grounding.enabled"
+	// ScanGroundingEnabledDefault is the default value for matching scanned ingredients against the food database
+	ScanGroundingEnabledDefault = true
+	// ScanGroundingEnabledHelp is the help message for the grounding flag
+	ScanGroundingEnabledHelp = "Match scanned ingredients against the food database and compute the macros of matches from their reference values"
+
+	// ScanGroundingMinScoreArg is the flag name for the minimum ingredient match score
+	ScanGroundingMinScoreArg = scanKey + "grounding.min-score"
+	// ScanGroundingMinScoreDefault is the default minimum ingredient match score
+	ScanGroundingMinScoreDefault = 0.75
+	// ScanGroundingMinScoreHelp is the help message for the minimum ingredient match score flag
+	ScanGroundingMinScoreHelp = "Word similarity (0-1) of the ingredient and food names required to replace the AI estimate"
+
+	// Prompts
+	promptsKey = "prompts."
+	// PromptsDirArg is the flag name for the prompt directory
+	PromptsDirArg = promptsKey + "dir"
+	// PromptsDirDefault is the default prompt directory, empty uses the prompts embedded in the binary
+	PromptsDirDefault = ""
+	// PromptsDirHelp is the help message for the prompt directory flag
+	PromptsDirHelp = "Directory containing the .prompt files (empty uses the prompts embedded in the binary)"
+
+	// PromptsFoodScanVariantsArg is the flag name for the food scan prompt variants
+	PromptsFoodScanVariantsArg = promptsKey + "food-scan.variants"
+	// PromptsFoodScanVariantsHelp is the help message for the food scan prompt variants flag
+	PromptsFoodScanVariantsHelp = "Variants of the food scan prompt (foodScan.<variant>.prompt) assigned at random per scan for A/B testing, \"default\" selects foodScan.prompt"
+
+	// Eval
+	evalKey = "eval."
+	// EvalDatasetArg is the flag name for the evaluation dataset directory
+	EvalDatasetArg = evalKey + "dataset"
+	// EvalDatasetDefault is the default evaluation dataset directory
+	EvalDatasetDefault = ""
+	// EvalDatasetHelp is the help message for the evaluation dataset directory flag
+	EvalDatasetHelp = "Directory of labeled images (<case>.json label next to the <case>.jpg image) to evaluate the food scan against"
+
+	// EvalResponsesArg is the flag name for the recorded responses directory
+	EvalResponsesArg = evalKey + "responses"
+	// EvalResponsesDefault is the default recorded responses directory, empty runs the food scan flow
+	EvalResponsesDefault = ""
+	// EvalResponsesHelp is the help message for the recorded responses directory flag
+	EvalResponsesHelp = "Directory of recorded scan results (<case>.json) replayed instead of calling the AI model (empty runs the food scan flow)"
+
+	// EvalOutputArg is the flag name for the evaluation report path
+	EvalOutputArg = evalKey + "output"
+	// EvalOutputDefault is the default evaluation report path, empty skips the JSON report
+	EvalOutputDefault = ""
+	// EvalOutputHelp is the help message for the evaluation report path flag
+	EvalOutputHelp = "Path of the JSON evaluation report (empty only prints the console table)"
+
+	// EvalConcurrencyArg is the flag name for the number of parallel evaluation scans
+	EvalConcurrencyArg = evalKey + "concurrency"
+	// EvalConcurrencyDefault is the default number of parallel evaluation scans
+	EvalConcurrencyDefault = 4
+	// EvalConcurrencyHelp is the help message for the number of parallel evaluation scans flag
+	EvalConcurrencyHelp = "Number of dataset images scanned in parallel"
+
+	// Import
+	importKey = "import."
+	// ImportBatchSizeArg is the flag name for the number of foods written per database request
+	ImportBatchSizeArg = importKey + "batch-size"
+	// ImportBatchSizeDefault is the default number of foods written per database request
+	ImportBatchSizeDefault = 500
+	// ImportBatchSizeHelp is the help message for the import batch size flag
+	ImportBatchSizeHelp = "Number of foods written to the database per request when importing food data"
+
+	// Foods
+	foodsKey = "foods."
+	// FoodsBarcodeLookupURLArg is the flag name for the base URL of the product API
+	FoodsBarcodeLookupURLArg = foodsKey + "barcode-lookup.url"
+	// FoodsBarcodeLookupURLDefault is the default base URL of the product API (lookups disabled)
+	FoodsBarcodeLookupURLDefault = ""
+	// FoodsBarcodeLookupURLHelp is the help message for the product API base URL flag
+	FoodsBarcodeLookupURLHelp = "Base URL of the Open Food Facts product API used for barcodes missing in the food database, e.g. https://world.openfoodfacts.org (empty disables lookups)"
+
+	// FoodsBarcodeLookupTimeoutArg is the flag name for the product API timeout
+	FoodsBarcodeLookupTimeoutArg = foodsKey + "barcode-lookup.timeout"
+	// FoodsBarcodeLookupTimeoutDefault is the default product API timeout
+	FoodsBarcodeLookupTimeoutDefault = 5 * time.Second
+	// FoodsBarcodeLookupTimeoutHelp is the help message for the product API timeout flag
+	FoodsBarcodeLookupTimeoutHelp = "Maximum duration of a product API lookup"
+
+	// Health
+	healthKey = "health."
+	// HealthCheckTimeoutArg is the flag name for the timeout of a single readiness check
+	HealthCheckTimeoutArg = healthKey + "check-timeout"
+	// HealthCheckTimeoutDefault is the default timeout of a single readiness check
+	HealthCheckTimeoutDefault = 2 * time.Second
+	// HealthCheckTimeoutHelp is the help message for the readiness check timeout flag
+	HealthCheckTimeoutHelp = "Maximum duration of a single dependency check of the readiness probe, slower dependencies are reported as down"
+
+	// Metrics
+	metricsKey = "metrics."
+	// MetricsEnabledArg is the flag name for enabling the Prometheus metrics endpoint
+	MetricsEnabledArg = metricsKey + "enabled"
+	// MetricsEnabledDefault is the default value for the Prometheus metrics endpoint
+	MetricsEnabledDefault = false
+	// MetricsEnabledHelp is the help message for the metrics flag
+	MetricsEnabledHelp = "Collect Prometheus metrics of requests, scans and database calls and expose them on the metrics path"
+
+	// MetricsPathArg is the flag name for the path of the metrics endpoint
+	MetricsPathArg = metricsKey + "path"
+	// MetricsPathDefault is the default path of the metrics endpoint
+	MetricsPathDefault = "/metrics"
+	// MetricsPathHelp is the help message for the metrics path flag
+	MetricsPathHelp = "Path the Prometheus metrics are served on"
+
+	// Tracing
+	tracingKey = "tracing."
+	// TracingExporterArg is the flag name for the span exporter
+	TracingExporterArg = tracingKey + "exporter"
+	// TracingExporterDefault is the default span exporter (tracing disabled)
+	TracingExporterDefault = "none"
+	// TracingExporterHelp is the help message for the span exporter flag
+	TracingExporterHelp = "Exporter of the OpenTelemetry spans of requests, scans and database calls (none, otlp or stdout)"
+
+	// TracingOTLPEndpointArg is the flag name for the OTLP collector URL
+	TracingOTLPEndpointArg = tracingKey + "otlp.endpoint"
+	// TracingOTLPEndpointDefault is the default OTLP collector URL (OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318)
+	TracingOTLPEndpointDefault = ""
+	// TracingOTLPEndpointHelp is the help message for the OTLP collector URL flag
+	TracingOTLPEndpointHelp = "URL of the OTLP/HTTP collector, e.g. http://localhost:4318 (empty uses OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318)"
+
+	// TracingFileArg is the flag name for the file of the stdout exporter
+	TracingFileArg = tracingKey + "file"
+	// TracingFileDefault is the default file of the stdout exporter (stdout)
+	TracingFileDefault = ""
+	// TracingFileHelp is the help message for the trace file flag
+	TracingFileHelp = "File the stdout exporter appends the spans to as JSON (empty writes to stdout)"
+
+	// TracingSampleRatioArg is the flag name for the fraction of traces recorded
+	TracingSampleRatioArg = tracingKey + "sample-ratio"
+	// TracingSampleRatioDefault is the default fraction of traces recorded
+	TracingSampleRatioDefault = 1.0
+	// TracingSampleRatioHelp is the help message for the sample ratio flag
+	TracingSampleRatioHelp = "Fraction of the traces recorded, traces continued from a sampled client are always recorded"
+
 	// OpenAPI
 	openapiKey = "openapi."
 	// OpenAPIPathArg is the flag name for the OpenAPI path
@@ -84,6 +383,55 @@ const (
 	// DevMocksScanFoodHelp is the help message for the mock scan food flag
 	DevMocksScanFoodHelp = "Enable mock AI response for image scanning"
 
+	// DevMocksScanFileArg is the flag name for the mock scan responses file
+	DevMocksScanFileArg = devKey + "mocks.scan.file"
+	// DevMocksScanFileDefault is the default mock scan responses file, empty uses the built-in sample meals
+	DevMocksScanFileDefault = ""
+	// DevMocksScanFileHelp is the help message for the mock scan responses file flag
+	DevMocksScanFileHelp = "YAML or JSON file with the mock scan responses (empty uses the built-in sample meals)"
+
+	// DevMocksScanSelectionArg is the flag name for the mock scan response selection
+	DevMocksScanSelectionArg = devKey + "mocks.scan.selection"
+	// DevMocksScanSelectionDefault is the default mock scan response selection
+	DevMocksScanSelectionDefault = "round-robin"
+	// DevMocksScanSelectionHelp is the help message for the mock scan response selection flag
+	DevMocksScanSelectionHelp = "How mock scan responses are selected (round-robin, seed, keyword)"
+
+	// DevMocksScanSeedArg is the flag name for the mock scan selection seed
+	DevMocksScanSeedArg = devKey + "mocks.scan.seed"
+	// DevMocksScanSeedDefault is the default mock scan selection seed
+	DevMocksScanSeedDefault = 1
+	// DevMocksScanSeedHelp is the help message for the mock scan selection seed flag
+	DevMocksScanSeedHelp = "Seed of the pseudo random mock scan selection, the same seed returns the same sequence"
+
+	// DevFixturesModeArg is the flag name for the scan fixture mode
+	DevFixturesModeArg = devKey + "fixtures.mode"
+	// DevFixturesModeDefault is the default scan fixture mode
+	DevFixturesModeDefault = "off"
+	// DevFixturesModeHelp is the help message for the scan fixture mode flag
+	DevFixturesModeHelp = "Record scan results to fixture files or replay them instead of calling the AI model (off, record, replay)"
+
+	// DevFixturesDirArg is the flag name for the scan fixture directory
+	DevFixturesDirArg = devKey + "fixtures.dir"
+	// DevFixturesDirDefault is the default scan fixture directory
+	DevFixturesDirDefault = "testdata/fixtures"
+	// DevFixturesDirHelp is the help message for the scan fixture directory flag
+	DevFixturesDirHelp = "Directory of the scan fixture files, named by the SHA-256 hash of the image and a hash of the description and prompt version"
+
+	// DevMocksFoodsFileArg is the flag name for the food data loaded into the mock food database
+	DevMocksFoodsFileArg = devKey + "mocks.foods.file"
+	// DevMocksFoodsFileDefault is the default food data of the mock food database
+	DevMocksFoodsFileDefault = "testdata/usda/foods.json"
+	// DevMocksFoodsFileHelp is the help message for the mock food data flag
+	DevMocksFoodsFileHelp = "FoodData Central JSON file or CSV directory loaded into the in-memory food database of the mock nutrition service (empty starts without foods)"
+
+	// DevMocksProductsFileArg is the flag name for the products loaded into the mock food database
+	DevMocksProductsFileArg = devKey + "mocks.products.file"
+	// DevMocksProductsFileDefault is the default products of the mock food database
+	DevMocksProductsFileDefault = "testdata/off/products.jsonl"
+	// DevMocksProductsFileHelp is the help message for the mock products flag
+	DevMocksProductsFileHelp = "Open Food Facts JSONL dump loaded into the in-memory food database of the mock nutrition service (empty starts without products)"
+
 	// Supabase
 	supabaseKey = "supabase."
 	// SupabaseURLArg is the flag name for the Supabase URL
@@ -104,6 +452,15 @@ const (
 var (
 	// ServerOriginDefault is the default server origin
 	ServerOriginDefault = []string{"http://localhost:3000"}
+
+	// ServerRouteMaxBodyBytesDefault is the default per route request body limit.
+	// The JSON scan endpoints carry the image base64 encoded, which inflates it by a third.
+	ServerRouteMaxBodyBytesDefault = map[string]int{
+		"scan-food":        14 << 20,
+		"scan-food-stream": 14 << 20,
+		"scan-food-upload": 11 << 20,
+		"clarify-scan":     14 << 20,
+	}
 )
 
 func RegisterFlags(cmd *cobra.Command) {
@@ -117,6 +474,65 @@ func RegisterFlags(cmd *cobra.Command) {
 	pflags.String(ServerAddrArg, ServerAddrDefault, ServerAddrHelp)
 	pflags.StringSlice(ServerOriginArg, ServerOriginDefault, ServerOriginHelp)
 	pflags.Duration(ServerShutdownTimeoutArg, ServerShutdownTimeoutDefault, ServerShutdownTimeoutHelp)
+	pflags.Int64(ServerMaxBodyBytesArg, ServerMaxBodyBytesDefault, ServerMaxBodyBytesHelp)
+	pflags.StringToInt(ServerRouteMaxBodyBytesArg, ServerRouteMaxBodyBytesDefault, ServerRouteMaxBodyBytesHelp)
+
+	// AI
+	pflags.String(AIProviderArg, AIProviderDefault, AIProviderHelp)
+	pflags.String(AIModelArg, AIModelDefault, AIModelHelp)
+	pflags.StringSlice(AIFallbackModelsArg, nil, AIFallbackModelsHelp)
+	pflags.Float64(AITemperatureArg, AITemperatureDefault, AITemperatureHelp)
+	pflags.String(AIAPIKeyArg, AIAPIKeyDefault, AIAPIKeyHelp)
+	pflags.String(AIAPIKeyEnvArg, AIAPIKeyEnvDefault, AIAPIKeyEnvHelp)
+	pflags.String(AIBaseURLArg, AIBaseURLDefault, AIBaseURLHelp)
+	pflags.String(AIVertexProjectArg, AIVertexProjectDefault, AIVertexProjectHelp)
+	pflags.String(AIVertexLocationArg, AIVertexLocationDefault, AIVertexLocationHelp)
+
+	// Scan
+	pflags.Int64(ScanMaxImageBytesArg, ScanMaxImageBytesDefault, ScanMaxImageBytesHelp)
+	pflags.Int(ScanMaxImageDimensionArg, ScanMaxImageDimensionDefault, ScanMaxImageDimensionHelp)
+	pflags.Bool(ScanCacheEnabledArg, ScanCacheEnabledDefault, ScanCacheEnabledHelp)
+	pflags.Int(ScanCacheSizeArg, ScanCacheSizeDefault, ScanCacheSizeHelp)
+	pflags.Duration(ScanCacheTTLArg, ScanCacheTTLDefault, ScanCacheTTLHelp)
+	pflags.Int(ScanRetryMaxAttemptsArg, ScanRetryMaxAttemptsDefault, ScanRetryMaxAttemptsHelp)
+	pflags.Duration(ScanRetryInitialBackoffArg, ScanRetryInitialBackoffDefault, ScanRetryInitialBackoffHelp)
+	pflags.Duration(ScanRetryMaxBackoffArg, ScanRetryMaxBackoffDefault, ScanRetryMaxBackoffHelp)
+	pflags.Float64(ScanRetryMultiplierArg, ScanRetryMultiplierDefault, ScanRetryMultiplierHelp)
+	pflags.Float64(ScanRetryJitterArg, ScanRetryJitterDefault, ScanRetryJitterHelp)
+	pflags.Duration(ScanTimeoutArg, ScanTimeoutDefault, ScanTimeoutHelp)
+	pflags.Float64(ScanClarificationThresholdArg, ScanClarificationThresholdDefault, ScanClarificationThresholdHelp)
+	pflags.Bool(ScanGroundingEnabledArg, ScanGroundingEnabledDefault, ScanGroundingEnabledHelp)
+	pflags.Float64(ScanGroundingMinScoreArg, ScanGroundingMinScoreDefault, ScanGroundingMinScoreHelp)
+
+	// Prompts
+	pflags.String(PromptsDirArg, PromptsDirDefault, PromptsDirHelp)
+	pflags.StringSlice(PromptsFoodScanVariantsArg, nil, PromptsFoodScanVariantsHelp)
+
+	// Eval
+	pflags.String(EvalDatasetArg, EvalDatasetDefault, EvalDatasetHelp)
+	pflags.String(EvalResponsesArg, EvalResponsesDefault, EvalResponsesHelp)
+	pflags.String(EvalOutputArg, EvalOutputDefault, EvalOutputHelp)
+	pflags.Int(EvalConcurrencyArg, EvalConcurrencyDefault, EvalConcurrencyHelp)
+
+	// Import
+	pflags.Int(ImportBatchSizeArg, ImportBatchSizeDefault, ImportBatchSizeHelp)
+
+	// Foods
+	pflags.String(FoodsBarcodeLookupURLArg, FoodsBarcodeLookupURLDefault, FoodsBarcodeLookupURLHelp)
+	pflags.Duration(FoodsBarcodeLookupTimeoutArg, FoodsBarcodeLookupTimeoutDefault, FoodsBarcodeLookupTimeoutHelp)
+
+	// Health
+	pflags.Duration(HealthCheckTimeoutArg, HealthCheckTimeoutDefault, HealthCheckTimeoutHelp)
+
+	// Metrics
+	pflags.Bool(MetricsEnabledArg, MetricsEnabledDefault, MetricsEnabledHelp)
+	pflags.String(MetricsPathArg, MetricsPathDefault, MetricsPathHelp)
+
+	// Tracing
+	pflags.String(TracingExporterArg, TracingExporterDefault, TracingExporterHelp)
+	pflags.String(TracingOTLPEndpointArg, TracingOTLPEndpointDefault, TracingOTLPEndpointHelp)
+	pflags.String(TracingFileArg, TracingFileDefault, TracingFileHelp)
+	pflags.Float64(TracingSampleRatioArg, TracingSampleRatioDefault, TracingSampleRatioHelp)
 
 	// OpenAPI
 	pflags.String(OpenAPIPathArg, OpenAPIPathDefault, OpenAPIPathHelp)
@@ -126,6 +542,13 @@ func RegisterFlags(cmd *cobra.Command) {
 	pflags.Bool(DevModeEnabledArg, DevModeEnabledDefault, DevModeEnabledHelp)
 	pflags.Bool(DevMocksNutritionServiceArg, DevMocksNutritionServiceDefault, DevMocksNutritionServiceHelp)
 	pflags.Bool(DevMocksScanFoodArg, DevMocksScanFoodDefault, DevMocksScanFoodHelp)
+	pflags.String(DevMocksScanFileArg, DevMocksScanFileDefault, DevMocksScanFileHelp)
+	pflags.String(DevMocksScanSelectionArg, DevMocksScanSelectionDefault, DevMocksScanSelectionHelp)
+	pflags.Uint64(DevMocksScanSeedArg, DevMocksScanSeedDefault, DevMocksScanSeedHelp)
+	pflags.String(DevFixturesModeArg, DevFixturesModeDefault, DevFixturesModeHelp)
+	pflags.String(DevFixturesDirArg, DevFixturesDirDefault, DevFixturesDirHelp)
+	pflags.String(DevMocksFoodsFileArg, DevMocksFoodsFileDefault, DevMocksFoodsFileHelp)
+	pflags.String(DevMocksProductsFileArg, DevMocksProductsFileDefault, DevMocksProductsFileHelp)
 
 	// Supabase
 	pflags.String(SupabaseURLArg, SupabaseURLDefault, SupabaseURLHelp)
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/dogab/vitalstack/api/internal/middleware"
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// FoodServicer is an interface for the food database
+type FoodServicer interface {
+	SearchFoods(ctx context.Context, query string, limit int) ([]service.Food, error)
+	GetFood(ctx context.Context, userID string, id int64) (*service.Food, error)
+	ListPersonalFoods(ctx context.Context, userID string) ([]service.Food, error)
+	GetFoodByBarcode(ctx context.Context, barcode string) (*service.Food, error)
+	LogProduct(ctx context.Context, input *service.LogProductInput) (*service.LogFoodOutput, error)
+}
+
+// FoodController is a controller for the food database
+type FoodController struct {
+	Service FoodServicer
+	// Units provides the measurement systems preferred by the users, without it quantities are metric
+	Units UnitsPreferencer
+}
+
+// NewFoodController creates a new food controller
+func NewFoodController(service FoodServicer, prefs UnitsPreferencer) *FoodController {
+	return &FoodController{Service: service, Units: prefs}
+}
+
+func (c *FoodController) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		Path:        "/api/foods/search",
+		Method:      http.MethodGet,
+		OperationID: "search-foods",
+		Summary:     "Search foods",
+		Description: "Fuzzy search the reference food database by name, best matches first. Macros and nutrients are per 100 g.",
+		Tags:        []string{"foods"},
+	}, c.SearchFoodsHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/foods/personal",
+		Method:      http.MethodGet,
+		OperationID: "list-personal-foods",
+		Summary:     "List personal foods",
+		Description: "Fetch the user's personal foods, e.g. products read from nutrition labels, newest first. Macros and nutrients are per 100 g.",
+		Tags:        []string{"foods"},
+	}, c.ListPersonalFoodsHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/foods/{id}",
+		Method:      http.MethodGet,
+		OperationID: "get-food",
+		Summary:     "Get food",
+		Description: "Fetch a reference food or a personal food of the user by ID. Macros and nutrients are per 100 g.",
+		Tags:        []string{"foods"},
+	}, c.GetFoodHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/foods/barcode/{ean}",
+		Method:      http.MethodGet,
+		OperationID: "get-food-by-barcode",
+		Summary:     "Get product by barcode",
+		Description: "Fetch a packaged product by the EAN/UPC barcode on the package. Macros and nutrients are per 100 g, " +
+			"the ingredient is one label serving (100 g if the label has no serving size).",
+		Tags: []string{"foods"},
+	}, c.GetFoodByBarcodeHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/foods/barcode/{ean}/log",
+		Method:      http.MethodPost,
+		OperationID: "log-product",
+		Summary:     "Log product by barcode",
+		Description: "Save a packaged product to the user's diet log, by the number of label servings or by weight.",
+		Tags:        []string{"foods"},
+	}, c.LogProductHandler)
+}
+
+// SearchFoodsHandler handles the food search
+func (c *FoodController) SearchFoodsHandler(ctx context.Context, input *SearchFoodsInput) (*SearchFoodsOutput, error) {
+	foods, err := c.Service.SearchFoods(ctx, input.Query, input.Limit)
+	if err != nil {
+		return nil, convertServiceErrorToHTTPError(err)
+	}
+
+	body := &SearchFoodsOutputBody{Foods: make([]FoodBody, len(foods))}
+	for i := range foods {
+		body.Foods[i] = newFoodBody(&foods[i])
+	}
+	return &SearchFoodsOutput{Body: body}, nil
+}
+
+// GetFoodHandler handles fetching a single food
+func (c *FoodController) GetFoodHandler(ctx context.Context, input *GetFoodInput) (*GetFoodOutput, error) {
+	uid, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	id, err := strconv.ParseInt(input.ID, 10, 64)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid food ID format")
+	}
+
+	food, err := c.Service.GetFood(ctx, uid, id)
+	if err != nil {
+		return nil, convertServiceErrorToHTTPError(err)
+	}
+
+	body := newFoodBody(food)
+	return &GetFoodOutput{Body: &body}, nil
+}
+
+// ListPersonalFoodsHandler handles listing the personal foods of the user
+func (c *FoodController) ListPersonalFoodsHandler(ctx context.Context, input *struct{}) (*ListPersonalFoodsOutput, error) {
+	uid, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	foods, err := c.Service.ListPersonalFoods(ctx, uid)
+	if err != nil {
+		return nil, convertServiceErrorToHTTPError(err)
+	}
+
+	body := &ListPersonalFoodsOutputBody{Foods: make([]FoodBody, len(foods))}
+	for i := range foods {
+		body.Foods[i] = newFoodBody(&foods[i])
+	}
+	return &ListPersonalFoodsOutput{Body: body}, nil
+}
+
+// GetFoodByBarcodeHandler handles fetching a packaged product by barcode
+func (c *FoodController) GetFoodByBarcodeHandler(ctx context.Context, input *GetFoodByBarcodeInput) (*GetFoodByBarcodeOutput, error) {
+	food, err := c.Service.GetFoodByBarcode(ctx, input.EAN)
+	if err != nil {
+		return nil, convertServiceErrorToHTTPError(err)
+	}
+
+	grams := 100.0
+	if food.Serving != nil {
+		grams = food.Serving.Grams
+	}
+	ing := food.Ingredient(grams)
+	return &GetFoodByBarcodeOutput{
+		Body: &ProductBody{
+			FoodBody:   newFoodBody(food),
+			Ingredient: newIngredientBody(&ing, unitsSystem(ctx, c.Units, &input.UnitsInput)),
+		},
+	}, nil
+}
+
+// LogProductHandler handles logging a packaged product
+func (c *FoodController) LogProductHandler(ctx context.Context, input *LogProductInput) (*LogFoodOutput, error) {
+	uid, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	resp, err := c.Service.LogProduct(ctx, &service.LogProductInput{
+		UserID:   &uid,
+		Barcode:  input.EAN,
+		Servings: input.Body.Servings,
+		Grams:    input.Body.Grams,
+	})
+	if err != nil {
+		return nil, convertServiceErrorToHTTPError(err)
+	}
+
+	return &LogFoodOutput{
+		Body: &LogFoodOutputBody{
+			Success: resp.Success,
+			ID:      resp.ID,
+		},
+	}, nil
+}
+
+// newFoodBody maps a service food to the HTTP food
+func newFoodBody(food *service.Food) FoodBody {
+	body := FoodBody{
+		ID:       strconv.FormatInt(food.ID, 10),
+		Name:     food.Name,
+		Category: food.Category,
+		Source:   string(food.Source),
+		SourceID: food.SourceID,
+		Per100g:  *newMacroData(food.Per100g),
+		Barcode:  food.Barcode,
+		Brand:    food.Brand,
+	}
+	if food.Serving != nil {
+		body.Serving = &ServingBody{
+			Size:  food.Serving.Size,
+			Grams: food.Serving.Grams,
+			Unit:  food.Serving.Unit,
+		}
+	}
+	return body
+}
@@ -0,0 +1,84 @@
+package controller
+
+// SearchFoodsInput represents the food search request
+type SearchFoodsInput struct {
+	Query string `query:"q" required:"true" minLength:"2" example:"chicken breast" doc:"Name of the food to search for"`
+	Limit int    `query:"limit" default:"20" minimum:"1" maximum:"100" doc:"Maximum number of foods to return"`
+}
+
+// SearchFoodsOutput represents the food search response
+type SearchFoodsOutput struct {
+	Body *SearchFoodsOutputBody `json:"body"`
+}
+
+type SearchFoodsOutputBody struct {
+	Foods []FoodBody `json:"foods" doc:"Matching foods, best matches first"`
+}
+
+// GetFoodInput represents the request to fetch a food
+type GetFoodInput struct {
+	ID string `path:"id" doc:"ID of the food"`
+}
+
+// GetFoodOutput represents the food response
+type GetFoodOutput struct {
+	Body *FoodBody `json:"body"`
+}
+
+// ListPersonalFoodsOutput represents the personal foods response
+type ListPersonalFoodsOutput struct {
+	Body *ListPersonalFoodsOutputBody `json:"body"`
+}
+
+type ListPersonalFoodsOutputBody struct {
+	Foods []FoodBody `json:"foods" doc:"Personal foods of the user, newest first"`
+}
+
+// FoodBody represents a reference food of the food database
+type FoodBody struct {
+	ID       string    `json:"id" example:"42" doc:"Database ID of the food"`
+	Name     string    `json:"name" example:"Chicken, broiler or fryers, breast, skinless, boneless, meat only, cooked, braised" doc:"Food name"`
+	Category string    `json:"category,omitempty" example:"Poultry Products" doc:"Food category"`
+	Source   string    `json:"source" example:"usda" doc:"Source of the reference data (usda, off, or label for personal foods)"`
+	SourceID string    `json:"source_id" example:"2646171" doc:"ID of the food in the source, e.g. the FoodData Central ID"`
+	Per100g  MacroData `json:"per_100g" doc:"Macros and nutrients per 100 g of the food"`
+
+	Barcode string       `json:"barcode,omitempty" example:"3017620422003" doc:"EAN/UPC barcode of a packaged product"`
+	Brand   string       `json:"brand,omitempty" example:"Ferrero" doc:"Brand of a packaged product"`
+	Serving *ServingBody `json:"serving,omitempty" doc:"Serving size from the label of a packaged product"`
+}
+
+// ServingBody represents the serving size printed on the label of a packaged product
+type ServingBody struct {
+	Size  string  `json:"size,omitempty" example:"15 g" doc:"Serving as printed on the label"`
+	Grams float64 `json:"grams" example:"15" doc:"Weight of a serving in grams, or volume in ml"`
+	Unit  string  `json:"unit" enum:"g,ml" example:"g" doc:"Unit of the serving weight"`
+}
+
+// GetFoodByBarcodeInput represents the request to fetch a packaged product
+type GetFoodByBarcodeInput struct {
+	UnitsInput
+	EAN string `path:"ean" example:"3017620422003" doc:"EAN-8, UPC-A, EAN-13 or GTIN-14 barcode of the product"`
+}
+
+// GetFoodByBarcodeOutput represents the packaged product response
+type GetFoodByBarcodeOutput struct {
+	Body *ProductBody `json:"body"`
+}
+
+// ProductBody represents a packaged product with the ingredient of a label serving
+type ProductBody struct {
+	FoodBody
+	Ingredient IngredientBody `json:"ingredient" doc:"One label serving of the product (100 g without serving size) as an ingredient"`
+}
+
+// LogProductInput represents the request to log a packaged product
+type LogProductInput struct {
+	EAN  string               `path:"ean" example:"3017620422003" doc:"EAN-8, UPC-A, EAN-13 or GTIN-14 barcode of the product"`
+	Body *LogProductInputBody `json:"body"`
+}
+
+type LogProductInputBody struct {
+	Servings float64 `json:"servings,omitempty" minimum:"0" example:"2" doc:"Number of label servings eaten, defaults to 1"`
+	Grams    float64 `json:"grams,omitempty" minimum:"0" example:"30" doc:"Weight eaten in grams, overrides servings. Required for products without serving size"`
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// HealthServicer is an interface for the checks of the dependencies
+type HealthServicer interface {
+	Ready(ctx context.Context) *service.HealthReport
+}
+
+// HealthController is a controller for the liveness and readiness probes
+type HealthController struct {
+	Service HealthServicer
+}
+
+// NewHealthController creates a new health controller
+func NewHealthController(service HealthServicer) *HealthController {
+	return &HealthController{Service: service}
+}
+
+func (c *HealthController) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		Path:        "/api/health/live",
+		Method:      http.MethodGet,
+		OperationID: "health-live",
+		Summary:     "Liveness probe",
+		Description: "Returns ok while the server is able to answer requests. Dependencies are not checked, so that a failing database does not restart the server.",
+		Tags:        []string{"health"},
+	}, c.LiveHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/health/ready",
+		Method:      http.MethodGet,
+		OperationID: "health-ready",
+		Summary:     "Readiness probe",
+		Description: "Checks the dependencies (database, AI provider, scan cache) with a timeout each and reports their status. Answers 503 if any dependency is down.",
+		Tags:        []string{"health"},
+		Responses: map[string]*huma.Response{
+			"503": {
+				Description: "A dependency is down",
+				Content: map[string]*huma.MediaType{
+					"application/json": {Schema: api.OpenAPI().Components.Schemas.Schema(reflect.TypeOf(ReadinessBody{}), true, "")},
+				},
+			},
+		},
+	}, c.ReadyHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/health",
+		Method:      http.MethodGet,
+		OperationID: "health",
+		Summary:     "Health check",
+		Description: "Alias of the liveness probe, use /api/health/live or /api/health/ready instead.",
+		Tags:        []string{"health"},
+		Deprecated:  true,
+	}, c.LiveHandler)
+}
+
+// LiveHandler handles the liveness probe
+func (c *HealthController) LiveHandler(ctx context.Context, _ *struct{}) (*HealthOutput, error) {
+	return &HealthOutput{Body: &HealthBody{Status: "ok"}}, nil
+}
+
+// ReadyHandler handles the readiness probe
+func (c *HealthController) ReadyHandler(ctx context.Context, _ *struct{}) (*ReadinessOutput, error) {
+	report := c.Service.Ready(ctx)
+
+	body := &ReadinessBody{Status: "ok", Components: make(map[string]ComponentHealthBody, len(report.Components))}
+	for name, health := range report.Components {
+		component := ComponentHealthBody{
+			Status:    "up",
+			LatencyMs: float64(health.Latency.Microseconds()) / 1000,
+			Error:     health.Error,
+		}
+		if !health.Up {
+			component.Status = "down"
+		}
+		body.Components[name] = component
+	}
+
+	status := http.StatusOK
+	if !report.Ready {
+		body.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	}
+	return &ReadinessOutput{Status: status, Body: body}, nil
+}
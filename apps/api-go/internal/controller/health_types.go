@@ -0,0 +1,29 @@
+package controller
+
+// HealthOutput represents the liveness response
+type HealthOutput struct {
+	Body *HealthBody `json:"body"`
+}
+
+type HealthBody struct {
+	Status string `json:"status" enum:"ok" example:"ok" doc:"Always ok while the server is able to answer"`
+}
+
+// ReadinessOutput represents the readiness response, 503 if a dependency is down
+type ReadinessOutput struct {
+	Status int
+	Body   *ReadinessBody `json:"body"`
+}
+
+// ReadinessBody represents the results of the health checks
+type ReadinessBody struct {
+	Status     string                         `json:"status" enum:"ok,unavailable" example:"ok" doc:"ok if all dependencies are up, otherwise unavailable"`
+	Components map[string]ComponentHealthBody `json:"components" doc:"Health of the dependencies by name, e.g. database, ai_provider and scan_cache"`
+}
+
+// ComponentHealthBody represents the health of a dependency
+type ComponentHealthBody struct {
+	Status    string  `json:"status" enum:"up,down" example:"up" doc:"Whether the dependency is usable"`
+	LatencyMs float64 `json:"latency_ms" example:"12.5" doc:"Duration of the check in milliseconds"`
+	Error     string  `json:"error,omitempty" example:"timed out after 2s" doc:"Failure of the check"`
+}
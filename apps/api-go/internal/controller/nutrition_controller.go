@@ -2,31 +2,43 @@ package controller
 
 import (
 	"context"
-	"fmt"
+	"encoding/base64"
+	"io"
+	"math"
 	"net/http"
 	"strconv"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/sse"
 	"github.com/dogab/vitalstack/api/internal/middleware"
 	"github.com/dogab/vitalstack/api/pkg/service"
+	"github.com/dogab/vitalstack/api/pkg/units"
 )
 
 // NutritionServicer is an interface for nutrition services
 type NutritionServicer interface {
 	ScanFood(ctx context.Context, input *service.ScanInput) (*service.ScanOutput, error)
+	ScanFoodStream(ctx context.Context, input *service.ScanInput, onProgress service.ScanProgressFunc) (*service.ScanOutput, error)
+	ScanLabel(ctx context.Context, userID string, input *service.ScanInput) (*service.LabelScanOutput, error)
+	ClarifyScan(ctx context.Context, input *service.ScanInput, answers []service.ClarificationAnswer) (*service.ScanOutput, error)
 	LogFood(ctx context.Context, input *service.LogFoodInput) (*service.LogFoodOutput, error)
 	GetDailyIntake(ctx context.Context, userID string, tzOffsetMins int) (*service.DailyIntakeOutput, error)
 	DeleteLoggedFood(ctx context.Context, userID string, logID int64) error
 }
 
+// scanModeLabel reads a nutrition label instead of estimating a meal
+const scanModeLabel = "label"
+
 // NutritionController is a controller for nutrition services
 type NutritionController struct {
 	Service NutritionServicer
+	// Units provides the measurement systems preferred by the users, without it quantities are metric
+	Units UnitsPreferencer
 }
 
 // NewNutritionController creates a new nutrition controller
-func NewNutritionController(service NutritionServicer) *NutritionController {
-	return &NutritionController{Service: service}
+func NewNutritionController(service NutritionServicer, prefs UnitsPreferencer) *NutritionController {
+	return &NutritionController{Service: service, Units: prefs}
 }
 
 func (c *NutritionController) Register(api huma.API) {
@@ -34,11 +46,47 @@ func (c *NutritionController) Register(api huma.API) {
 		Path:        "/api/nutrition/scan",
 		Method:      http.MethodPost,
 		OperationID: "scan-food",
-		Summary:     "Scan food",
-		Description: "Scan food",
+		Summary:     "Scan food image for nutritional information",
+		Description: "Upload a base64-encoded food image and optionally provide a description. Returns detected food name and macro breakdown. With `mode=label` the image is read as a nutrition label instead.",
 		Tags:        []string{"nutrition"},
 	}, c.ScanHandler)
 
+	huma.Register(api, huma.Operation{
+		Path:        "/api/nutrition/scan/upload",
+		Method:      http.MethodPost,
+		OperationID: "scan-food-upload",
+		Summary:     "Scan food (multipart upload)",
+		Description: "Upload a food image as multipart/form-data and optionally provide a description. Returns detected food name and macro breakdown. With `mode=label` the image is read as a nutrition label instead.",
+		Tags:        []string{"nutrition"},
+	}, c.ScanUploadHandler)
+
+	sse.Register(api, huma.Operation{
+		Path:        "/api/nutrition/scan/stream",
+		Method:      http.MethodPost,
+		OperationID: "scan-food-stream",
+		Summary:     "Scan food (streaming)",
+		Description: "Scan food and stream the progress as Server-Sent Events: `analysing` when the analysis starts, `ingredient` for every recognized ingredient and `result` with the totals. Failures are sent as an `error` event.",
+		Tags:        []string{"nutrition"},
+	}, scanStreamEvents, c.ScanStreamHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/nutrition/scan/clarify",
+		Method:      http.MethodPost,
+		OperationID: "clarify-scan",
+		Summary:     "Clarify food scan",
+		Description: "Scan the food again with the answers to the clarifying questions of a low-confidence scan appended to the description.",
+		Tags:        []string{"nutrition"},
+	}, c.ClarifyScanHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/nutrition/nutrients",
+		Method:      http.MethodGet,
+		OperationID: "list-nutrients",
+		Summary:     "List tracked nutrients",
+		Description: "Returns the catalog of nutrients tracked beyond the macros with their units. Nutrient amounts in scans and logs are keyed by these IDs.",
+		Tags:        []string{"nutrition"},
+	}, c.ListNutrientsHandler)
+
 	huma.Register(api, huma.Operation{
 		Path:        "/api/nutrition/log",
 		Method:      http.MethodPost,
@@ -68,51 +116,206 @@ func (c *NutritionController) Register(api huma.API) {
 }
 
 // ScanHandler handles the scan request
-func (c *NutritionController) ScanHandler(ctx context.Context, input *ScanInput) (*ScanOutput, error) {
-	out := &ScanOutput{Body: &ScanOutputBody{}}
+func (c *NutritionController) ScanHandler(ctx context.Context, input *ScanFoodInput) (*ScanOutput, error) {
+	req := &service.ScanInput{
+		ImageBase64: input.Body.ImageBase64,
+		Description: input.Body.Description,
+	}
+	return c.scan(ctx, input.Mode, unitsSystem(ctx, c.Units, &input.UnitsInput), req)
+}
+
+// ScanUploadHandler handles the multipart scan request
+func (c *NutritionController) ScanUploadHandler(ctx context.Context, input *ScanUploadInput) (*ScanOutput, error) {
+	form := input.RawBody.Data()
+
+	//nolint:errcheck // the file is only read from
+	defer form.Image.Close()
+
+	image, err := io.ReadAll(form.Image)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Failed to read uploaded image", err)
+	}
 
+	req := &service.ScanInput{
+		ImageBase64:   base64.StdEncoding.EncodeToString(image),
+		ImageMimeType: form.Image.ContentType,
+	}
+	if form.Description != "" {
+		req.Description = &form.Description
+	}
+	return c.scan(ctx, input.Mode, unitsSystem(ctx, c.Units, &input.UnitsInput), req)
+}
+
+// scan scans a meal or, in label mode, reads a nutrition label into the user's personal foods
+func (c *NutritionController) scan(ctx context.Context, mode string, system units.System, req *service.ScanInput) (*ScanOutput, error) {
+	if mode != scanModeLabel {
+		resp, err := c.Service.ScanFood(ctx, req)
+		if err != nil {
+			return nil, convertServiceErrorToHTTPError(err)
+		}
+		return &ScanOutput{Body: newScanOutputBody(resp, system)}, nil
+	}
+
+	uid, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	resp, err := c.Service.ScanLabel(ctx, uid, req)
+	if err != nil {
+		return nil, convertServiceErrorToHTTPError(err)
+	}
+
+	body := newScanOutputBody(resp.Scan, system)
+	product := newFoodBody(resp.Food)
+	body.Product = &product
+	return &ScanOutput{Body: body}, nil
+}
+
+// ScanStreamHandler handles the streaming scan request
+func (c *NutritionController) ScanStreamHandler(ctx context.Context, input *ScanInput, send sse.Sender) {
+	req := &service.ScanInput{
+		ImageBase64: input.Body.ImageBase64,
+		Description: input.Body.Description,
+	}
+	system := unitsSystem(ctx, c.Units, &input.UnitsInput)
+
+	started := false
+	resp, err := c.Service.ScanFoodStream(ctx, req, func(_ context.Context, progress *service.ScanProgress) error {
+		switch progress.Stage {
+		case service.ScanStageAnalysing:
+			event := ScanAnalysingEvent{Message: "Analysing image", Restart: started}
+			started = true
+			return send.Data(event)
+		case service.ScanStageIngredient:
+			return send.Data(newIngredientBody(progress.Ingredient, system))
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		sendStreamError(send, convertServiceErrorToHTTPError(err))
+		return
+	}
+
+	//nolint:errcheck // nothing left to do if the client has gone away
+	send.Data(newScanOutputBody(resp, system))
+}
+
+// ClarifyScanHandler handles the scan request with answers to the clarifying questions
+func (c *NutritionController) ClarifyScanHandler(ctx context.Context, input *ClarifyScanInput) (*ScanOutput, error) {
 	req := &service.ScanInput{
 		ImageBase64: input.Body.ImageBase64,
 		Description: input.Body.Description,
 	}
-	resp, err := c.Service.ScanFood(ctx, req)
+	answers := make([]service.ClarificationAnswer, len(input.Body.Answers))
+	for i, a := range input.Body.Answers {
+		answers[i] = service.ClarificationAnswer{Question: a.Question, Answer: a.Answer}
+	}
+
+	resp, err := c.Service.ClarifyScan(ctx, req, answers)
 	if err != nil {
 		return nil, convertServiceErrorToHTTPError(err)
 	}
 
+	return &ScanOutput{Body: newScanOutputBody(resp, unitsSystem(ctx, c.Units, &input.UnitsInput))}, nil
+}
+
+// servingSize describes the total weight of the scan, it is empty if no ingredient has a weight
+func servingSize(resp *service.ScanOutput, system units.System) string {
+	weight := resp.TotalWeight()
+	if weight == 0 {
+		return ""
+	}
+	return units.Format(system.FoodWeight(float64(weight)))
+}
+
+// newScanOutputBody maps a service scan result to the HTTP response body in the measurement system
+func newScanOutputBody(resp *service.ScanOutput, system units.System) *ScanOutputBody {
 	// Compute totals from ingredients
 	totals := resp.TotalMacros()
+	band := resp.CalorieBand()
+
+	body := &ScanOutputBody{
+		FoodName:      resp.FoodName,
+		Confidence:    resp.Confidence,
+		Macros:        newMacroData(totals),
+		ServingSize:   servingSize(resp, system),
+		PromptVersion: resp.PromptVersion,
 
-	out.Body.FoodName = resp.FoodName
-	out.Body.Confidence = resp.Confidence
-	out.Body.Macros = &MacroData{
-		Calories: totals.Calories,
-		Protein:  totals.Protein,
-		Carbs:    totals.Carbs,
-		Fat:      totals.Fat,
-		Fiber:    totals.Fiber,
+		CaloriesRange:       &RangeBody{Low: float64(band.Low), High: float64(band.High)},
+		CaloriesUncertainty: band.Uncertainty,
+
+		NeedsClarification:  resp.NeedsClarification,
+		ClarifyingQuestions: resp.ClarifyingQuestions,
+		Alternatives:        resp.Alternatives,
 	}
-	out.Body.ServingSize = fmt.Sprintf("%dg", resp.TotalWeight())
 
 	// Map ingredients from service to controller type
-	out.Body.Ingredients = make([]IngredientBody, len(resp.Ingredients))
-	for i, ing := range resp.Ingredients {
-		out.Body.Ingredients[i] = IngredientBody{
-			Name:            ing.Name,
-			ServingSize:     ing.ServingSize,
-			ServingQuantity: ing.ServingQuantity,
-			ServingUnit:     ing.ServingUnit,
-			Macros: &MacroData{
-				Calories: ing.Calories,
-				Protein:  ing.Protein,
-				Carbs:    ing.Carbs,
-				Fat:      ing.Fat,
-				Fiber:    ing.Fiber,
-			},
-		}
+	body.Ingredients = make([]IngredientBody, len(resp.Ingredients))
+	for i := range resp.Ingredients {
+		body.Ingredients[i] = newIngredientBody(&resp.Ingredients[i], system)
 	}
 
-	return out, nil
+	return body
+}
+
+// newIngredientBody maps a service ingredient to the HTTP ingredient in the measurement system. The
+// confidence and ranges are omitted for ingredients without estimates, e.g. of mocked or older recorded scans.
+func newIngredientBody(ing *service.Ingredient, system units.System) IngredientBody {
+	body := IngredientBody{
+		Name:            ing.Name,
+		ServingSize:     ing.ServingSize,
+		ServingQuantity: ing.ServingQuantity,
+		ServingUnit:     ing.ServingUnit,
+		Macros: &MacroData{
+			Calories:  ing.Calories,
+			Protein:   ing.Protein,
+			Carbs:     ing.Carbs,
+			Fat:       ing.Fat,
+			Fiber:     ing.Fiber,
+			Nutrients: service.NewNutrientValues(ing.Nutrients),
+		},
+		Source: string(ing.Source),
+	}
+	if ing.FoodID != 0 {
+		foodID := strconv.FormatInt(ing.FoodID, 10)
+		body.FoodID = &foodID
+	}
+	if ing.Confidence > 0 {
+		body.Confidence = &ing.Confidence
+	}
+	if ing.Grams > 0 {
+		body.Grams = &ing.Grams
+	}
+	if ing.Grams > 0 && ing.GramsLow > 0 && ing.GramsHigh >= ing.GramsLow {
+		low, high := ing.CalorieRange()
+		body.GramsRange = &RangeBody{Low: ing.GramsLow, High: ing.GramsHigh}
+		body.CaloriesRange = &RangeBody{Low: math.Round(low), High: math.Round(high)}
+	}
+	renderServing(&body, system)
+	return body
+}
+
+// newMacroData maps service macros to the HTTP macros
+func newMacroData(m service.MacroData) *MacroData {
+	return &MacroData{
+		Calories:  m.Calories,
+		Protein:   m.Protein,
+		Carbs:     m.Carbs,
+		Fat:       m.Fat,
+		Fiber:     m.Fiber,
+		Nutrients: m.Nutrients,
+	}
+}
+
+// ListNutrientsHandler returns the nutrient catalog
+func (c *NutritionController) ListNutrientsHandler(ctx context.Context, input *struct{}) (*NutrientCatalogOutput, error) {
+	nutrients := make([]NutrientBody, len(service.NutrientCatalog))
+	for i, n := range service.NutrientCatalog {
+		nutrients[i] = NutrientBody{ID: n.ID, Name: n.Name, Unit: n.Unit}
+	}
+	return &NutrientCatalogOutput{Body: &NutrientCatalogOutputBody{Nutrients: nutrients}}, nil
 }
 
 // LogFoodHandler handles saving an accepted scan to the database
@@ -136,6 +339,15 @@ func (c *NutritionController) LogFoodHandler(ctx context.Context, input *LogFood
 			Carbs:           ing.Macros.Carbs,
 			Fat:             ing.Macros.Fat,
 			Fiber:           ing.Macros.Fiber,
+			Nutrients:       service.NutrientValues(ing.Macros.Nutrients).Amounts(),
+			Source:          service.IngredientSource(ing.Source),
+		}
+		if ing.FoodID != nil {
+			foodID, err := strconv.ParseInt(*ing.FoodID, 10, 64)
+			if err != nil {
+				return nil, huma.Error400BadRequest("Invalid food ID format")
+			}
+			serviceIngredients[i].FoodID = foodID
 		}
 	}
 
@@ -144,13 +356,15 @@ func (c *NutritionController) LogFoodHandler(ctx context.Context, input *LogFood
 		FoodName:   input.Body.FoodName,
 		Confidence: input.Body.Confidence,
 		Macros: service.MacroData{
-			Calories: input.Body.Macros.Calories,
-			Protein:  input.Body.Macros.Protein,
-			Carbs:    input.Body.Macros.Carbs,
-			Fat:      input.Body.Macros.Fat,
-			Fiber:    input.Body.Macros.Fiber,
+			Calories:  input.Body.Macros.Calories,
+			Protein:   input.Body.Macros.Protein,
+			Carbs:     input.Body.Macros.Carbs,
+			Fat:       input.Body.Macros.Fat,
+			Fiber:     input.Body.Macros.Fiber,
+			Nutrients: input.Body.Macros.Nutrients,
 		},
-		Ingredients: serviceIngredients,
+		Ingredients:   serviceIngredients,
+		PromptVersion: input.Body.PromptVersion,
 	}
 
 	resp, err := c.Service.LogFood(ctx, serviceReq)
@@ -180,38 +394,21 @@ func (c *NutritionController) GetDailyIntakeHandler(ctx context.Context, input *
 	}
 
 	// Map Service DTOs to HTTP Output
+	system := unitsSystem(ctx, c.Units, &input.UnitsInput)
 	meals := make([]Meal, len(resp.Meals))
 	for i, m := range resp.Meals {
 		// Map service ingredients to controller IngredientBody
 		ingBodies := make([]IngredientBody, len(m.Ingredients))
-		for j, ing := range m.Ingredients {
-			ingBodies[j] = IngredientBody{
-				Name:            ing.Name,
-				ServingSize:     ing.ServingSize,
-				ServingQuantity: ing.ServingQuantity,
-				ServingUnit:     ing.ServingUnit,
-				Macros: &MacroData{
-					Calories: ing.Calories,
-					Protein:  ing.Protein,
-					Carbs:    ing.Carbs,
-					Fat:      ing.Fat,
-					Fiber:    ing.Fiber,
-				},
-			}
+		for j := range m.Ingredients {
+			ingBodies[j] = newIngredientBody(&m.Ingredients[j], system)
 		}
 
 		meals[i] = Meal{
-			ID:       m.ID,
-			Name:     m.Name,
-			Time:     m.Time,
-			Calories: m.Calories,
-			Macros: MacroData{
-				Calories: m.Macros.Calories,
-				Protein:  m.Macros.Protein,
-				Carbs:    m.Macros.Carbs,
-				Fat:      m.Macros.Fat,
-				Fiber:    m.Macros.Fiber,
-			},
+			ID:          m.ID,
+			Name:        m.Name,
+			Time:        m.Time,
+			Calories:    m.Calories,
+			Macros:      *newMacroData(m.Macros),
 			Emoji:       m.Emoji,
 			Tag:         m.Tag,
 			Ingredients: ingBodies,
@@ -220,14 +417,8 @@ func (c *NutritionController) GetDailyIntakeHandler(ctx context.Context, input *
 
 	return &DailyIntakeOutput{
 		Body: &DailyIntakeOutputBody{
-			Macros: MacroData{
-				Calories: resp.Macros.Calories,
-				Protein:  resp.Macros.Protein,
-				Carbs:    resp.Macros.Carbs,
-				Fat:      resp.Macros.Fat,
-				Fiber:    resp.Macros.Fiber,
-			},
-			Meals: meals,
+			Macros: *newMacroData(resp.Macros),
+			Meals:  meals,
 		},
 	}, nil
 }
@@ -1,7 +1,17 @@
 package controller
 
+import "github.com/danielgtaylor/huma/v2"
+
 // ScanInput represents the scan request body
 type ScanInput struct {
+	UnitsInput
+	Body *ScanInputBody `json:"body"`
+}
+
+// ScanFoodInput represents the scan request with the scan mode
+type ScanFoodInput struct {
+	UnitsInput
+	Mode string         `query:"mode" enum:"meal,label" default:"meal" doc:"meal estimates the macros of a meal photo, label reads the nutrition facts of a packaged food label and stores the product in the user's personal foods"`
 	Body *ScanInputBody `json:"body"`
 }
 
@@ -10,6 +20,37 @@ type ScanInputBody struct {
 	Description *string `json:"description,omitempty" doc:"Optional meal description for better AI analysis"`
 }
 
+// ClarifyScanInput represents the request to scan the food again with answers to the clarifying questions
+type ClarifyScanInput struct {
+	UnitsInput
+	Body *ClarifyScanInputBody `json:"body"`
+}
+
+type ClarifyScanInputBody struct {
+	ImageBase64 string                    `json:"image_base64" required:"true" doc:"Base64 encoded image data of the original scan"`
+	Description *string                   `json:"description,omitempty" doc:"Meal description of the original scan"`
+	Answers     []ClarificationAnswerBody `json:"answers" required:"true" minItems:"1" doc:"Answers to the clarifying questions of the original scan"`
+}
+
+// ClarificationAnswerBody is the answer of the user to a clarifying question
+type ClarificationAnswerBody struct {
+	Question string `json:"question" required:"true" example:"Is this whole milk or skim?" doc:"Clarifying question as returned by the scan"`
+	Answer   string `json:"answer" required:"true" minLength:"1" example:"Skim milk" doc:"Answer of the user"`
+}
+
+// ScanUploadInput represents the multipart scan request
+type ScanUploadInput struct {
+	UnitsInput
+	Mode    string `query:"mode" enum:"meal,label" default:"meal" doc:"meal estimates the macros of a meal photo, label reads the nutrition facts of a packaged food label and stores the product in the user's personal foods"`
+	RawBody huma.MultipartFormFiles[ScanUploadForm]
+}
+
+// ScanUploadForm holds the decoded multipart form fields of a scan upload
+type ScanUploadForm struct {
+	Image       huma.FormFile `form:"image" contentType:"image/jpeg,image/png,image/webp,image/heic,image/heif" required:"true" doc:"Food image file"`
+	Description string        `form:"description" doc:"Optional meal description for better AI analysis"`
+}
+
 // MacroData represents nutritional macro information
 type MacroData struct {
 	Calories int     `json:"calories" example:"450" doc:"Total calories"`
@@ -17,6 +58,8 @@ type MacroData struct {
 	Carbs    float64 `json:"carbs" example:"45.0" doc:"Carbohydrates in grams"`
 	Fat      float64 `json:"fat" example:"15.5" doc:"Fat in grams"`
 	Fiber    float64 `json:"fiber" example:"5.0" doc:"Fiber in grams"`
+
+	Nutrients map[string]float64 `json:"nutrients,omitempty" example:"{\"sodium\":420,\"sugar\":6.5}" doc:"Nutrients beyond the macros keyed by nutrient ID, in the unit of the nutrient catalog. Nutrients the AI could not estimate are omitted."`
 }
 
 // IngredientBody represents a single food component with its nutritional data
@@ -24,8 +67,20 @@ type IngredientBody struct {
 	Name            string     `json:"name" example:"Grilled Chicken Breast" doc:"Ingredient name"`
 	ServingSize     *int       `json:"serving_size,omitempty" example:"150" doc:"Raw serving size generic value"`
 	ServingQuantity *float64   `json:"serving_quantity,omitempty" example:"1.5" doc:"Quantity of the serving"`
-	ServingUnit     *string    `json:"serving_unit,omitempty" example:"g" doc:"Unit of the serving size (e.g., g, ml)"`
+	ServingUnit     *string    `json:"serving_unit,omitempty" example:"g" doc:"Unit of the serving size (e.g., g, ml), masses and volumes are rendered in the requested measurement system"`
 	Macros          *MacroData `json:"macros" doc:"Nutritional macro information for this ingredient"`
+	Confidence      *float64   `json:"confidence,omitempty" example:"0.85" doc:"Certainty of the ingredient and its portion (0-1)"`
+	Grams           *float64   `json:"grams,omitempty" example:"150" doc:"Estimated weight in grams the macros refer to"`
+	GramsRange      *RangeBody `json:"grams_range,omitempty" doc:"Plausible weight range in grams"`
+	CaloriesRange   *RangeBody `json:"calories_range,omitempty" doc:"Calories scaled to the plausible weight range"`
+	Source          string     `json:"source,omitempty" enum:"ai_estimate,database,label" example:"database" doc:"Whether the macros are estimated by the AI, computed from the weight and a matched food of the food database, or read from a nutrition label"`
+	FoodID          *string    `json:"food_id,omitempty" example:"42" doc:"ID of the matched food of the food database"`
+}
+
+// RangeBody is a plausible range around an estimate
+type RangeBody struct {
+	Low  float64 `json:"low" example:"120" doc:"Lowest plausible value"`
+	High float64 `json:"high" example:"190" doc:"Highest plausible value"`
 }
 
 // ScanOutput represents the scan response
@@ -34,11 +89,27 @@ type ScanOutput struct {
 }
 
 type ScanOutputBody struct {
-	FoodName    string           `json:"food_name" example:"Grilled Chicken Salad" doc:"Detected food name"`
-	Confidence  float64          `json:"confidence" example:"0.92" doc:"Detection confidence score"`
-	Macros      *MacroData       `json:"macros" doc:"Nutritional macro information"`
-	ServingSize string           `json:"serving_size" example:"1 plate (350g)" doc:"Estimated serving size"`
-	Ingredients []IngredientBody `json:"ingredients" doc:"Breakdown of individual ingredients with their macros"`
+	FoodName      string           `json:"food_name" example:"Grilled Chicken Salad" doc:"Detected food name"`
+	Confidence    float64          `json:"confidence" example:"0.92" doc:"Detection confidence score"`
+	Macros        *MacroData       `json:"macros" doc:"Nutritional macro information"`
+	ServingSize   string           `json:"serving_size" example:"350g" doc:"Estimated total weight of the ingredients in g or oz, empty if no ingredient has a weight"`
+	Ingredients   []IngredientBody `json:"ingredients" doc:"Breakdown of individual ingredients with their macros"`
+	PromptVersion string           `json:"prompt_version,omitempty" example:"food-scan-2026-10-4" doc:"Version of the prompt which produced the scan. Pass it on when logging the scan."`
+
+	CaloriesRange       *RangeBody `json:"calories_range" doc:"Plausible range of the total calories"`
+	CaloriesUncertainty int        `json:"calories_uncertainty" example:"80" doc:"Half width of the calorie range, e.g. 520 ± 80 kcal"`
+
+	NeedsClarification  bool     `json:"needs_clarification" example:"false" doc:"True if the confidence is below the clarification threshold. Answer the clarifying questions with the clarify endpoint to improve the estimate."`
+	ClarifyingQuestions []string `json:"clarifying_questions,omitempty" example:"[\"Is this whole milk or skim?\"]" doc:"Questions to the user which would most improve a low-confidence estimate"`
+	Alternatives        []string `json:"alternatives,omitempty" example:"[\"Chicken Caesar Salad\"]" doc:"Other dishes the food could be, most likely first, for low-confidence scans"`
+
+	Product *FoodBody `json:"product,omitempty" doc:"Product read from the nutrition label (mode=label), stored in the user's personal foods. The ingredient is one serving of it."`
+}
+
+// ScanAnalysingEvent is streamed when the analysis of the image starts
+type ScanAnalysingEvent struct {
+	Message string `json:"message" example:"Analysing image" doc:"Human readable progress message"`
+	Restart bool   `json:"restart" example:"false" doc:"True if the analysis was restarted (e.g. after a transient AI error). Previously streamed ingredients must be discarded."`
 }
 
 // LogFoodInput represents the request to log a specific food
@@ -47,11 +118,12 @@ type LogFoodInput struct {
 }
 
 type LogFoodInputBody struct {
-	UserID      *string          `json:"user_id,omitempty" doc:"Optional UUID of the user logging the meal (defaults to auth context if implemented)"`
-	FoodName    string           `json:"food_name" example:"Grilled Chicken Salad" doc:"Detected food name"`
-	Confidence  float64          `json:"confidence" example:"0.92" doc:"Detection confidence score"`
-	Macros      *MacroData       `json:"macros" doc:"Nutritional macro information"`
-	Ingredients []IngredientBody `json:"ingredients" doc:"Breakdown of individual ingredients with their macros"`
+	UserID        *string          `json:"user_id,omitempty" doc:"Optional UUID of the user logging the meal (defaults to auth context if implemented)"`
+	FoodName      string           `json:"food_name" example:"Grilled Chicken Salad" doc:"Detected food name"`
+	Confidence    float64          `json:"confidence" example:"0.92" doc:"Detection confidence score"`
+	Macros        *MacroData       `json:"macros" doc:"Nutritional macro information"`
+	Ingredients   []IngredientBody `json:"ingredients" doc:"Breakdown of individual ingredients with their macros"`
+	PromptVersion string           `json:"prompt_version,omitempty" example:"food-scan-2026-10-4" doc:"Version of the prompt which produced the scan, as returned by the scan. Versions of prompts the API does not serve are not stored."`
 }
 
 // LogFoodOutput represents the log response
@@ -66,6 +138,7 @@ type LogFoodOutputBody struct {
 
 // DailyIntakeInput represents the request to get daily food logs
 type DailyIntakeInput struct {
+	UnitsInput
 	TzOffset int `query:"tz_offset" default:"0" doc:"Timezone offset in minutes (UTC - Local Time)"`
 }
 
@@ -91,6 +164,22 @@ type DailyIntakeOutputBody struct {
 	Meals  []Meal    `json:"meals" doc:"List of meals logged today"`
 }
 
+// NutrientCatalogOutput represents the nutrient catalog response
+type NutrientCatalogOutput struct {
+	Body *NutrientCatalogOutputBody `json:"body"`
+}
+
+type NutrientCatalogOutputBody struct {
+	Nutrients []NutrientBody `json:"nutrients" doc:"Tracked nutrients beyond the macros in display order"`
+}
+
+// NutrientBody describes a tracked nutrient
+type NutrientBody struct {
+	ID   string `json:"id" example:"sodium" doc:"Key of the nutrient in nutrient amounts"`
+	Name string `json:"name" example:"Sodium" doc:"Display name"`
+	Unit string `json:"unit" example:"mg" doc:"Unit of the nutrient amounts"`
+}
+
 // DeleteLogInput handles removing a scan
 type DeleteLogInput struct {
 	ID string `path:"id" doc:"ID of the logged meal to delete"`
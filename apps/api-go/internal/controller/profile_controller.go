@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/dogab/vitalstack/api/internal/middleware"
+	"github.com/dogab/vitalstack/api/pkg/service"
+	"github.com/dogab/vitalstack/api/pkg/units"
+)
+
+// ProfileServicer is an interface for profile services
+type ProfileServicer interface {
+	UnitsPreferencer
+	GetProfile(ctx context.Context, userID string) (*service.Profile, error)
+	UpdateProfile(ctx context.Context, input *service.UpdateProfileInput) (*service.Profile, error)
+}
+
+// ProfileController is a controller for the profiles of users
+type ProfileController struct {
+	Service ProfileServicer
+}
+
+// NewProfileController creates a new profile controller
+func NewProfileController(service ProfileServicer) *ProfileController {
+	return &ProfileController{Service: service}
+}
+
+func (c *ProfileController) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		Path:        "/api/profile",
+		Method:      http.MethodGet,
+		OperationID: "get-profile",
+		Summary:     "Get profile",
+		Description: "Fetch the user's profile with the body weight and height in the requested measurement system.",
+		Tags:        []string{"profile"},
+	}, c.GetProfileHandler)
+
+	huma.Register(api, huma.Operation{
+		Path:        "/api/profile",
+		Method:      http.MethodPatch,
+		OperationID: "update-profile",
+		Summary:     "Update profile",
+		Description: "Update the user's body measures and preferred measurement system. Omitted fields are kept.",
+		Tags:        []string{"profile"},
+	}, c.UpdateProfileHandler)
+}
+
+// GetProfileHandler handles fetching the profile of the user
+func (c *ProfileController) GetProfileHandler(ctx context.Context, input *GetProfileInput) (*ProfileOutput, error) {
+	uid, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	profile, err := c.Service.GetProfile(ctx, uid)
+	if err != nil {
+		return nil, convertServiceErrorToHTTPError(err)
+	}
+
+	return &ProfileOutput{Body: newProfileBody(profile, unitsSystem(ctx, c.Service, &input.UnitsInput))}, nil
+}
+
+// UpdateProfileHandler handles updating the profile of the user. The body measures are read in the
+// measurement system of the request, which is the new preference unless the request selects units.
+func (c *ProfileController) UpdateProfileHandler(ctx context.Context, input *UpdateProfileInput) (*ProfileOutput, error) {
+	uid, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	req := &service.UpdateProfileInput{UserID: uid, Age: input.Body.Age}
+	if input.Body.Units != nil {
+		system := units.System(*input.Body.Units)
+		req.Units = &system
+		if input.Units == "" && input.AcceptUnits == "" {
+			input.Units = *input.Body.Units
+		}
+	}
+
+	system := unitsSystem(ctx, c.Service, &input.UnitsInput)
+	if input.Body.Weight != nil {
+		weight := system.BodyWeightKg(*input.Body.Weight)
+		req.Weight = &weight
+	}
+	if input.Body.Height != nil {
+		height := system.HeightCm(*input.Body.Height)
+		req.Height = &height
+	}
+
+	profile, err := c.Service.UpdateProfile(ctx, req)
+	if err != nil {
+		return nil, convertServiceErrorToHTTPError(err)
+	}
+
+	return &ProfileOutput{Body: newProfileBody(profile, system)}, nil
+}
+
+// newProfileBody maps the profile to the HTTP profile in the measurement system
+func newProfileBody(profile *service.Profile, system units.System) *ProfileBody {
+	_, weightUnit := system.BodyWeight(0)
+	_, heightUnit := system.Height(0)
+	body := &ProfileBody{
+		Units:      string(profile.Units),
+		WeightUnit: weightUnit.Symbol,
+		HeightUnit: heightUnit.Symbol,
+		Age:        profile.Age,
+	}
+	if profile.Weight != nil {
+		weight := units.Round(system.BodyWeight(*profile.Weight))
+		body.Weight = &weight
+	}
+	if profile.Height != nil {
+		height := units.Round(system.Height(*profile.Height))
+		body.Height = &height
+	}
+	return body
+}
@@ -0,0 +1,34 @@
+package controller
+
+// GetProfileInput represents the request to fetch the profile
+type GetProfileInput struct {
+	UnitsInput
+}
+
+// UpdateProfileInput represents the request to update the profile
+type UpdateProfileInput struct {
+	UnitsInput
+	Body *UpdateProfileInputBody `json:"body"`
+}
+
+type UpdateProfileInputBody struct {
+	Weight *float64 `json:"weight,omitempty" exclusiveMinimum:"0" example:"72.5" doc:"Body weight in kg, or in lb if the request is imperial"`
+	Height *float64 `json:"height,omitempty" exclusiveMinimum:"0" example:"180" doc:"Body height in cm, or in inches if the request is imperial"`
+	Age    *int     `json:"age,omitempty" minimum:"1" maximum:"150" example:"34" doc:"Age in years"`
+	Units  *string  `json:"units,omitempty" enum:"metric,imperial" example:"imperial" doc:"Preferred measurement system, quantities in responses are rendered in it unless the request selects other units. The weight and height of this request are read in it as well."`
+}
+
+// ProfileOutput represents the profile response
+type ProfileOutput struct {
+	Body *ProfileBody `json:"body"`
+}
+
+// ProfileBody represents the profile of the user, rendered in the measurement system of the request
+type ProfileBody struct {
+	Units      string   `json:"units" enum:"metric,imperial" example:"metric" doc:"Preferred measurement system"`
+	Weight     *float64 `json:"weight,omitempty" example:"72.5" doc:"Body weight in the weight unit"`
+	WeightUnit string   `json:"weight_unit" enum:"kg,lb" example:"kg" doc:"Unit of the body weight"`
+	Height     *float64 `json:"height,omitempty" example:"180" doc:"Body height in the height unit"`
+	HeightUnit string   `json:"height_unit" enum:"cm,in" example:"cm" doc:"Unit of the body height"`
+	Age        *int     `json:"age,omitempty" example:"34" doc:"Age in years"`
+}
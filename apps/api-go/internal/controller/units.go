@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"math"
+
+	"github.com/dogab/vitalstack/api/internal/middleware"
+	"github.com/dogab/vitalstack/api/pkg/units"
+)
+
+// UnitsInput selects the measurement system of the quantities in a response. It is embedded in the
+// inputs of the operations returning quantities.
+type UnitsInput struct {
+	Units       string `query:"units" enum:"metric,imperial" doc:"Measurement system of the quantities in the response, overrides the Accept-Units header and the preference of the profile"`
+	AcceptUnits string `header:"Accept-Units" enum:"metric,imperial" doc:"Measurement system of the quantities in the response, overrides the preference of the profile"`
+}
+
+// UnitsPreferencer returns the measurement system preferred by a user, it is implemented by ProfileService
+type UnitsPreferencer interface {
+	GetUnits(ctx context.Context, userID string) (units.System, error)
+}
+
+// unitsSystem resolves the measurement system of the response: the units query parameter, the
+// Accept-Units header, the preference of the user or otherwise metric
+func unitsSystem(ctx context.Context, prefs UnitsPreferencer, input *UnitsInput) units.System {
+	for _, name := range []string{input.Units, input.AcceptUnits} {
+		if system, ok := units.ParseSystem(name); ok {
+			return system
+		}
+	}
+	if uid, ok := middleware.GetUserFromContext(ctx); ok && prefs != nil {
+		system, err := prefs.GetUnits(ctx, uid)
+		if err == nil {
+			return system
+		}
+		slog.WarnContext(ctx, "Failed to get the units preference, using metric", "error", err)
+	}
+	return units.Metric
+}
+
+// renderServing converts the serving of the ingredient to the measurement system. The serving size is
+// an integer, so converted servings in g or ml are one serving of that size and converted servings in
+// oz or fl oz are a quantity of single units, e.g. 1 oz × 5.3.
+func renderServing(body *IngredientBody, system units.System) {
+	if body.ServingSize == nil || body.ServingUnit == nil {
+		return
+	}
+	from, ok := units.Parse(*body.ServingUnit)
+	if !ok {
+		return
+	}
+	quantity := 1.0
+	if body.ServingQuantity != nil {
+		quantity = *body.ServingQuantity
+	}
+
+	amount, to := system.Serving(float64(*body.ServingSize)*quantity, from)
+	if to == from {
+		return
+	}
+	size, quantity := 1, units.Round(amount, to)
+	if to == units.Gram || to == units.Milliliter {
+		size, quantity = int(math.Round(amount)), 1
+	}
+	body.ServingSize, body.ServingQuantity, body.ServingUnit = &size, &quantity, &to.Symbol
+}
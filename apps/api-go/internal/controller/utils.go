@@ -1,19 +1,47 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
 	"github.com/dogab/vitalstack/api/pkg/types"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/sse"
 )
 
 var (
 	errDefaultInternalServerError = errors.New("something went wrong, please try again later")
 )
 
+// statusClientClosedRequest is the non-standard status (introduced by nginx) for requests
+// aborted by the client. It is only logged since the client no longer reads the response.
+const statusClientClosedRequest = 499
+
+// scanStreamEvents maps the SSE event names of the streaming scan to their payloads
+var scanStreamEvents = map[string]any{
+	"analysing":  ScanAnalysingEvent{},
+	"ingredient": IngredientBody{},
+	"result":     ScanOutputBody{},
+	"error":      huma.ErrorModel{},
+}
+
+// sendStreamError sends the error as SSE error event, since the status of a stream has already been sent
+func sendStreamError(send sse.Sender, err huma.StatusError) {
+	var model *huma.ErrorModel
+	if !errors.As(err, &model) {
+		model = &huma.ErrorModel{Status: err.GetStatus(), Detail: err.Error()}
+	}
+	//nolint:errcheck // nothing left to do if the client has gone away
+	send.Data(model)
+}
+
 func convertServiceErrorToHTTPError(err error) huma.StatusError {
+	// The client disconnected before the request completed
+	if errors.Is(err, context.Canceled) {
+		return huma.NewError(statusClientClosedRequest, "client closed request")
+	}
 	// Check if the error is a field validation error
 	var fieldValidationError types.FieldValidationError
 	if errors.As(err, &fieldValidationError) {
@@ -38,8 +66,16 @@ func convertServiceErrorToHTTPError(err error) huma.StatusError {
 			return huma.Error403Forbidden(serviceError.Error())
 		case http.StatusConflict:
 			return huma.Error409Conflict(serviceError.Error())
+		case http.StatusRequestEntityTooLarge:
+			return huma.Error413RequestEntityTooLarge(serviceError.Error())
 		case http.StatusTooManyRequests:
 			return huma.Error429TooManyRequests(serviceError.Error())
+		case http.StatusBadGateway:
+			return huma.Error502BadGateway(serviceError.Error())
+		case http.StatusServiceUnavailable:
+			return huma.Error503ServiceUnavailable(serviceError.Error())
+		case http.StatusGatewayTimeout:
+			return huma.Error504GatewayTimeout(serviceError.Error())
 		default:
 			return huma.Error500InternalServerError("internal server error", errDefaultInternalServerError)
 		}
@@ -0,0 +1,54 @@
+// Package logging adds request scoped attributes to slog records.
+//
+// Middlewares store attributes like the request ID in the request context with WithAttrs, and
+// ContextHandler adds them to every record logged with that context (slog.InfoContext etc.), so that
+// all log lines of a request can be correlated.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+type contextKey struct{}
+
+// WithAttrs returns a context whose log records carry the attributes in addition to those of ctx
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	return context.WithValue(ctx, contextKey{}, slices.Concat(Attrs(ctx), attrs))
+}
+
+// Attrs returns the attributes stored in the context
+func Attrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs
+}
+
+// ContextHandler is a slog.Handler adding the attributes stored in the context of a record
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps the handler to add the attributes stored in the record context
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+// Handle adds the attributes of the context to the record and passes it to the wrapped handler
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := Attrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler. The attributes of the context are added to the group.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/dogab/vitalstack/api/internal/logging"
+)
+
+const (
+	// RequestIDHeader is the header the request ID is read from and returned in
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDContextKey is the key used to store the request ID in the context
+	RequestIDContextKey contextKey = "request_id"
+
+	// maxRequestIDLength bounds request IDs passed by clients, longer IDs are replaced
+	maxRequestIDLength = 128
+)
+
+// RequestIDMiddleware assigns every request an ID: the X-Request-ID of the client or proxy, or a new
+// UUID. The ID is returned in the X-Request-ID response header, stored in the context and added to
+// all records logged with the request context.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := context.WithValue(c.Request.Context(), RequestIDContextKey, requestID)
+		ctx = logging.WithAttrs(ctx, slog.String("request_id", requestID))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts IDs of printable ASCII characters, so that they can be logged safely
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRequestIDFromContext extracts the request ID from the provided context
+func GetRequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(RequestIDContextKey).(string)
+	return requestID, ok && requestID != ""
+}
+
+// AccessLogMiddleware logs one line per request with the route, status, latency and user. Server
+// errors are logged as errors and client errors as warnings.
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if uid, ok := GetUserFromContext(c.Request.Context()); ok {
+			attrs = append(attrs, slog.String("user_id", uid))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
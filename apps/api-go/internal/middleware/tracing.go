@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.32.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dogab/vitalstack/api/internal/logging"
+)
+
+// tracerName is the instrumentation name of the server spans
+const tracerName = "github.com/dogab/vitalstack/api/internal/middleware"
+
+// TracingMiddleware starts a server span per request, continuing the trace of the W3C traceparent
+// header. The spans of the services, repositories and Genkit flows are nested under it, and the
+// trace ID is added to all records logged with the request context.
+func TracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer(tracerName)
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		// The route is resolved before the middleware runs, unknown routes are named by their method only
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.HTTPRoute(route),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsValid() {
+			ctx = logging.WithAttrs(ctx, slog.String("trace_id", sc.TraceID().String()))
+		}
+		if requestID, ok := GetRequestIDFromContext(ctx); ok {
+			span.SetAttributes(attribute.StringSlice("http.request.header.x-request-id", []string{requestID}))
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if uid, ok := GetUserFromContext(c.Request.Context()); ok {
+			span.SetAttributes(semconv.UserID(uid))
+		}
+	}
+}
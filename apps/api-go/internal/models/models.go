@@ -7,7 +7,10 @@ type Profile struct {
 	Weight    *float64  `json:"weight,omitempty"`
 	Height    *float64  `json:"height,omitempty"`
 	Age       *int      `json:"age,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+
+	// Units is the preferred measurement system, metric or imperial
+	Units string `json:"units,omitempty"`
 }
 
 type FoodLog struct {
@@ -20,6 +23,8 @@ type FoodLog struct {
 	Carbs               float64             `json:"carbs"`
 	Fat                 float64             `json:"fat"`
 	Fiber               float64             `json:"fiber"`
+	PromptVersion       *string             `json:"prompt_version,omitempty"`
+	Nutrients           map[string]float64  `json:"nutrients,omitempty"`
 	Ingredients         []FoodLogIngredient `json:"food_log_ingredients,omitempty"`
 	CreatedAt           time.Time           `json:"created_at,omitempty"`
 }
@@ -37,4 +42,31 @@ type FoodLogIngredient struct {
 	Fat             float64   `json:"fat"`
 	Fiber           float64   `json:"fiber"`
 	CreatedAt       time.Time `json:"created_at,omitempty"`
+
+	Nutrients map[string]float64 `json:"nutrients,omitempty"`
+	Source    *string            `json:"source,omitempty"`
+	FoodID    *int64             `json:"food_id,omitempty"`
+}
+
+// Food is a reference food of the food composition database. Macros and nutrients are per 100 g.
+type Food struct {
+	ID        int64              `json:"id,omitempty"`
+	Source    string             `json:"source"`
+	SourceID  string             `json:"source_id"`
+	Name      string             `json:"name"`
+	Category  *string            `json:"category,omitempty"`
+	Calories  float64            `json:"calories"`
+	Protein   float64            `json:"protein"`
+	Carbs     float64            `json:"carbs"`
+	Fat       float64            `json:"fat"`
+	Fiber     float64            `json:"fiber"`
+	Nutrients map[string]float64 `json:"nutrients,omitempty"`
+	// Packaged products are looked up by barcode, ServingGrams is the weight of the label serving
+	Barcode      *string  `json:"barcode,omitempty"`
+	Brand        *string  `json:"brand,omitempty"`
+	ServingSize  *string  `json:"serving_size,omitempty"`
+	ServingGrams *float64 `json:"serving_grams,omitempty"`
+	ServingUnit  *string  `json:"serving_unit,omitempty"`
+	// UserID is set for personal foods, e.g. read from a nutrition label, which only their user sees
+	UserID *string `json:"user_id,omitempty"`
 }
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dogab/vitalstack/api/internal/models"
+	"github.com/supabase-community/supabase-go"
+)
+
+// ErrFoodNotFound is returned for foods which do not exist
+var ErrFoodNotFound = errors.New("food not found")
+
+type FoodRepository interface {
+	// UpsertFoods inserts the foods or updates the existing foods with the same source and source ID
+	UpsertFoods(ctx context.Context, foods []models.Food) error
+	// SearchFoods returns up to limit foods matching the query, best matches first. A non-empty source
+	// only searches the foods of that source.
+	SearchFoods(ctx context.Context, query, source string, limit int) ([]models.Food, error)
+	GetFood(ctx context.Context, id int64) (*models.Food, error)
+	// GetFoodByBarcode returns the packaged product with the barcode
+	GetFoodByBarcode(ctx context.Context, barcode string) (*models.Food, error)
+	// SaveFood inserts the food or updates the existing food with the same source and source ID
+	SaveFood(ctx context.Context, food models.Food) (*models.Food, error)
+	// ListUserFoods returns the personal foods of the user, newest first
+	ListUserFoods(ctx context.Context, userID string) ([]models.Food, error)
+}
+
+type foodRepository struct {
+	client *supabase.Client
+}
+
+func NewFoodRepository(client *supabase.Client) FoodRepository {
+	return &foodRepository{client: client}
+}
+
+func (r *foodRepository) UpsertFoods(ctx context.Context, foods []models.Food) error {
+	if len(foods) == 0 {
+		return nil
+	}
+	_, _, err := r.client.From("foods").Upsert(foods, "source,source_id", "minimal", "").Execute()
+	return err
+}
+
+func (r *foodRepository) SearchFoods(ctx context.Context, query, source string, limit int) ([]models.Food, error) {
+	// The fuzzy matching and ranking is done by the search_foods database function (pg_trgm)
+	params := map[string]any{"query": query, "max_results": limit}
+	if source != "" {
+		params["food_source"] = source
+	}
+	data := r.client.Rpc("search_foods", "", params)
+
+	var foods []models.Food
+	if err := json.Unmarshal([]byte(data), &foods); err != nil {
+		// Failed calls answer with an error object instead of the list of foods
+		return nil, fmt.Errorf("failed to search foods: %s", data)
+	}
+	return foods, nil
+}
+
+func (r *foodRepository) GetFood(ctx context.Context, id int64) (*models.Food, error) {
+	data, _, err := r.client.From("foods").
+		Select("*", "", false).
+		Eq("id", strconv.FormatInt(id, 10)).
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var foods []models.Food
+	if err := json.Unmarshal(data, &foods); err != nil {
+		return nil, err
+	}
+	if len(foods) == 0 {
+		return nil, ErrFoodNotFound
+	}
+	return &foods[0], nil
+}
+
+func (r *foodRepository) GetFoodByBarcode(ctx context.Context, barcode string) (*models.Food, error) {
+	data, _, err := r.client.From("foods").
+		Select("*", "", false).
+		Eq("barcode", barcode).
+		Limit(1, "").
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var foods []models.Food
+	if err := json.Unmarshal(data, &foods); err != nil {
+		return nil, err
+	}
+	if len(foods) == 0 {
+		return nil, ErrFoodNotFound
+	}
+	return &foods[0], nil
+}
+
+func (r *foodRepository) SaveFood(ctx context.Context, food models.Food) (*models.Food, error) {
+	data, _, err := r.client.From("foods").Upsert(food, "source,source_id", "representation", "").Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var foods []models.Food
+	if err := json.Unmarshal(data, &foods); err != nil {
+		return nil, err
+	}
+	if len(foods) == 0 {
+		return nil, errors.New("no food returned after save")
+	}
+	return &foods[0], nil
+}
+
+func (r *foodRepository) ListUserFoods(ctx context.Context, userID string) ([]models.Food, error) {
+	data, _, err := r.client.From("foods").
+		Select("*", "", false).
+		Eq("user_id", userID).
+		Order("id", nil). // descending by default
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var foods []models.Food
+	if err := json.Unmarshal(data, &foods); err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
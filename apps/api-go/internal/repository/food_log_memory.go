@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dogab/vitalstack/api/internal/models"
+)
+
+// ErrFoodLogNotFound is returned for ingredients of food logs which do not exist
+var ErrFoodLogNotFound = errors.New("food log not found")
+
+// memoryFoodLogRepository keeps the food logs in memory, e.g. for the mock API in dev mode.
+// The logs are lost when the process exits.
+type memoryFoodLogRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	logs   []models.FoodLog
+}
+
+// NewMemoryFoodLogRepository creates an empty in-memory food log repository
+func NewMemoryFoodLogRepository() FoodLogRepository {
+	return &memoryFoodLogRepository{nextID: 1}
+}
+
+func (r *memoryFoodLogRepository) CreateFoodLog(ctx context.Context, log *models.FoodLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.ID = r.nextID
+	r.nextID++
+
+	stored := *log
+	stored.Ingredients = nil
+	r.logs = append(r.logs, stored)
+	return nil
+}
+
+func (r *memoryFoodLogRepository) CreateFoodLogIngredient(ctx context.Context, ingredient *models.FoodLogIngredient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Like the food_log_ingredients foreign key, ingredients of unknown logs are rejected
+	for i := range r.logs {
+		if r.logs[i].ID == ingredient.FoodLogID {
+			r.logs[i].Ingredients = append(r.logs[i].Ingredients, *ingredient)
+			return nil
+		}
+	}
+	return ErrFoodLogNotFound
+}
+
+func (r *memoryFoodLogRepository) GetDailyFoodLogs(ctx context.Context, userID string, startOfDay time.Time, endOfDay time.Time) ([]models.FoodLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var logs []models.FoodLog
+	for _, log := range r.logs {
+		if log.UserID == nil || *log.UserID != userID {
+			continue
+		}
+		if log.CreatedAt.Before(startOfDay) || log.CreatedAt.After(endOfDay) {
+			continue
+		}
+		log.Ingredients = append([]models.FoodLogIngredient(nil), log.Ingredients...)
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+func (r *memoryFoodLogRepository) DeleteFoodLog(ctx context.Context, userID string, logID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Like the database delete, logs of other users or unknown IDs are silently ignored
+	for i, log := range r.logs {
+		if log.ID == logID && log.UserID != nil && *log.UserID == userID {
+			r.logs = append(r.logs[:i], r.logs[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
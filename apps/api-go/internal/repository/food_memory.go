@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dogab/vitalstack/api/internal/models"
+)
+
+// memoryFoodRepository keeps the foods in memory, e.g. for the mock API in dev mode
+type memoryFoodRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	foods  []models.Food
+}
+
+// NewMemoryFoodRepository creates an empty in-memory food repository
+func NewMemoryFoodRepository() FoodRepository {
+	return &memoryFoodRepository{nextID: 1}
+}
+
+func (r *memoryFoodRepository) UpsertFoods(ctx context.Context, foods []models.Food) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, food := range foods {
+		r.upsert(food)
+	}
+	return nil
+}
+
+func (r *memoryFoodRepository) SaveFood(ctx context.Context, food models.Food) (*models.Food, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := r.upsert(food)
+	return &saved, nil
+}
+
+// upsert inserts the food or replaces the food with the same source and source ID, r.mu must be locked
+func (r *memoryFoodRepository) upsert(food models.Food) models.Food {
+	for i := range r.foods {
+		if r.foods[i].Source == food.Source && r.foods[i].SourceID == food.SourceID {
+			food.ID = r.foods[i].ID
+			r.foods[i] = food
+			return food
+		}
+	}
+	food.ID = r.nextID
+	r.nextID++
+	r.foods = append(r.foods, food)
+	return food
+}
+
+func (r *memoryFoodRepository) SearchFoods(ctx context.Context, query, source string, limit int) ([]models.Food, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	// Like the search_foods database function, foods containing the query words rank first
+	// and shorter (more generic) names win ties
+	type match struct {
+		food  models.Food
+		score float64
+	}
+	var matches []match
+	for _, food := range r.foods {
+		// Like the search_foods database function, personal foods and foods of other sources are not searched
+		if food.UserID != nil || (source != "" && food.Source != source) {
+			continue
+		}
+		name := strings.ToLower(food.Name)
+		found := 0
+		for _, word := range words {
+			if strings.Contains(name, word) {
+				found++
+			}
+		}
+		if found == 0 {
+			continue
+		}
+		matches = append(matches, match{food: food, score: float64(found) / float64(len(words))})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].food.Name) < len(matches[j].food.Name)
+	})
+
+	foods := make([]models.Food, 0, min(limit, len(matches)))
+	for i := 0; i < len(matches) && i < limit; i++ {
+		foods = append(foods, matches[i].food)
+	}
+	return foods, nil
+}
+
+func (r *memoryFoodRepository) GetFood(ctx context.Context, id int64) (*models.Food, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, food := range r.foods {
+		if food.ID == id {
+			return &food, nil
+		}
+	}
+	return nil, ErrFoodNotFound
+}
+
+func (r *memoryFoodRepository) GetFoodByBarcode(ctx context.Context, barcode string) (*models.Food, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, food := range r.foods {
+		if food.Barcode != nil && *food.Barcode == barcode {
+			return &food, nil
+		}
+	}
+	return nil, ErrFoodNotFound
+}
+
+func (r *memoryFoodRepository) ListUserFoods(ctx context.Context, userID string) ([]models.Food, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var foods []models.Food
+	for i := len(r.foods) - 1; i >= 0; i-- {
+		if r.foods[i].UserID != nil && *r.foods[i].UserID == userID {
+			foods = append(foods, r.foods[i])
+		}
+	}
+	return foods, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/supabase-community/supabase-go"
+)
+
+// HealthRepository checks the connection to the database
+type HealthRepository interface {
+	// Ping reads a single row, so that both the database and the credentials are checked
+	Ping(ctx context.Context) error
+}
+
+type healthRepository struct {
+	client *supabase.Client
+}
+
+// NewHealthRepository creates a health repository of the Supabase database
+func NewHealthRepository(client *supabase.Client) HealthRepository {
+	return &healthRepository{client: client}
+}
+
+func (r *healthRepository) Ping(ctx context.Context) error {
+	if r.client == nil {
+		return errors.New("supabase client is not configured")
+	}
+	_, _, err := r.client.From("foods").Select("id", "", false).Limit(1, "").Execute()
+	return err
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.32.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dogab/vitalstack/api/internal/models"
+)
+
+// tracerName is the instrumentation name of the repository spans
+const tracerName = "github.com/dogab/vitalstack/api/internal/repository"
+
+// Metrics records the latency and errors of repository calls
+type Metrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewMetrics creates the repository metrics and registers them with reg
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "vitalstack",
+			Subsystem: "repository",
+			Name:      "call_duration_seconds",
+			Help:      "Duration of repository calls by repository and method",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"repository", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "vitalstack",
+			Subsystem: "repository",
+			Name:      "call_errors_total",
+			Help:      "Number of failed repository calls by repository and method, missing records are not counted",
+		}, []string{"repository", "method"}),
+	}
+	reg.MustRegister(m.duration, m.errors)
+	return m
+}
+
+// observe runs the call in a span and records its latency and error if metrics are set. Not found
+// errors are expected results and not counted as failures.
+func observe[T any](ctx context.Context, m *Metrics, repository, method string, call func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, repository+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			attribute.String("repository.name", repository),
+			attribute.String("repository.method", method),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	result, err := call(ctx)
+	failed := err != nil && !isNotFound(err)
+	if failed {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if m != nil {
+		m.duration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+		if failed {
+			m.errors.WithLabelValues(repository, method).Inc()
+		}
+	}
+	return result, err
+}
+
+// observeErr runs a call returning only an error like observe
+func observeErr(ctx context.Context, m *Metrics, repository, method string, call func(ctx context.Context) error) error {
+	_, err := observe(ctx, m, repository, method, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, call(ctx)
+	})
+	return err
+}
+
+// isNotFound reports whether the error is a missing record
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrFoodNotFound) || errors.Is(err, ErrFoodLogNotFound) || errors.Is(err, ErrProfileNotFound)
+}
+
+// instrumentedFoodRepository traces the calls to a food repository and records their metrics
+type instrumentedFoodRepository struct {
+	repo    FoodRepository
+	metrics *Metrics
+}
+
+// InstrumentFoodRepository returns the food repository tracing its calls and recording their metrics, if set
+func InstrumentFoodRepository(repo FoodRepository, metrics *Metrics) FoodRepository {
+	return &instrumentedFoodRepository{repo: repo, metrics: metrics}
+}
+
+func (r *instrumentedFoodRepository) UpsertFoods(ctx context.Context, foods []models.Food) error {
+	return observeErr(ctx, r.metrics, "food", "UpsertFoods", func(ctx context.Context) error {
+		return r.repo.UpsertFoods(ctx, foods)
+	})
+}
+
+func (r *instrumentedFoodRepository) SearchFoods(ctx context.Context, query, source string, limit int) ([]models.Food, error) {
+	return observe(ctx, r.metrics, "food", "SearchFoods", func(ctx context.Context) ([]models.Food, error) {
+		return r.repo.SearchFoods(ctx, query, source, limit)
+	})
+}
+
+func (r *instrumentedFoodRepository) GetFood(ctx context.Context, id int64) (*models.Food, error) {
+	return observe(ctx, r.metrics, "food", "GetFood", func(ctx context.Context) (*models.Food, error) {
+		return r.repo.GetFood(ctx, id)
+	})
+}
+
+func (r *instrumentedFoodRepository) GetFoodByBarcode(ctx context.Context, barcode string) (*models.Food, error) {
+	return observe(ctx, r.metrics, "food", "GetFoodByBarcode", func(ctx context.Context) (*models.Food, error) {
+		return r.repo.GetFoodByBarcode(ctx, barcode)
+	})
+}
+
+func (r *instrumentedFoodRepository) SaveFood(ctx context.Context, food models.Food) (*models.Food, error) {
+	return observe(ctx, r.metrics, "food", "SaveFood", func(ctx context.Context) (*models.Food, error) {
+		return r.repo.SaveFood(ctx, food)
+	})
+}
+
+func (r *instrumentedFoodRepository) ListUserFoods(ctx context.Context, userID string) ([]models.Food, error) {
+	return observe(ctx, r.metrics, "food", "ListUserFoods", func(ctx context.Context) ([]models.Food, error) {
+		return r.repo.ListUserFoods(ctx, userID)
+	})
+}
+
+// instrumentedFoodLogRepository traces the calls to a food log repository and records their metrics
+type instrumentedFoodLogRepository struct {
+	repo    FoodLogRepository
+	metrics *Metrics
+}
+
+// InstrumentFoodLogRepository returns the food log repository tracing its calls and recording their metrics, if set
+func InstrumentFoodLogRepository(repo FoodLogRepository, metrics *Metrics) FoodLogRepository {
+	return &instrumentedFoodLogRepository{repo: repo, metrics: metrics}
+}
+
+func (r *instrumentedFoodLogRepository) CreateFoodLog(ctx context.Context, log *models.FoodLog) error {
+	return observeErr(ctx, r.metrics, "food_log", "CreateFoodLog", func(ctx context.Context) error {
+		return r.repo.CreateFoodLog(ctx, log)
+	})
+}
+
+func (r *instrumentedFoodLogRepository) CreateFoodLogIngredient(ctx context.Context, ingredient *models.FoodLogIngredient) error {
+	return observeErr(ctx, r.metrics, "food_log", "CreateFoodLogIngredient", func(ctx context.Context) error {
+		return r.repo.CreateFoodLogIngredient(ctx, ingredient)
+	})
+}
+
+func (r *instrumentedFoodLogRepository) GetDailyFoodLogs(ctx context.Context, userID string, startOfDay time.Time, endOfDay time.Time) ([]models.FoodLog, error) {
+	return observe(ctx, r.metrics, "food_log", "GetDailyFoodLogs", func(ctx context.Context) ([]models.FoodLog, error) {
+		return r.repo.GetDailyFoodLogs(ctx, userID, startOfDay, endOfDay)
+	})
+}
+
+func (r *instrumentedFoodLogRepository) DeleteFoodLog(ctx context.Context, userID string, logID int64) error {
+	return observeErr(ctx, r.metrics, "food_log", "DeleteFoodLog", func(ctx context.Context) error {
+		return r.repo.DeleteFoodLog(ctx, userID, logID)
+	})
+}
+
+// instrumentedUserRepository traces the calls to a user repository and records their metrics
+type instrumentedUserRepository struct {
+	repo    UserRepository
+	metrics *Metrics
+}
+
+// InstrumentUserRepository returns the user repository tracing its calls and recording their metrics, if set
+func InstrumentUserRepository(repo UserRepository, metrics *Metrics) UserRepository {
+	return &instrumentedUserRepository{repo: repo, metrics: metrics}
+}
+
+func (r *instrumentedUserRepository) GetProfile(ctx context.Context, id string) (*models.Profile, error) {
+	return observe(ctx, r.metrics, "user", "GetProfile", func(ctx context.Context) (*models.Profile, error) {
+		return r.repo.GetProfile(ctx, id)
+	})
+}
+
+func (r *instrumentedUserRepository) CreateProfile(ctx context.Context, profile *models.Profile) error {
+	return observeErr(ctx, r.metrics, "user", "CreateProfile", func(ctx context.Context) error {
+		return r.repo.CreateProfile(ctx, profile)
+	})
+}
+
+func (r *instrumentedUserRepository) UpdateProfile(ctx context.Context, profile *models.Profile) error {
+	return observeErr(ctx, r.metrics, "user", "UpdateProfile", func(ctx context.Context) error {
+		return r.repo.UpdateProfile(ctx, profile)
+	})
+}
@@ -2,11 +2,16 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 
 	"github.com/dogab/vitalstack/api/internal/models"
 	"github.com/supabase-community/supabase-go"
 )
 
+// ErrProfileNotFound is returned for users without a profile
+var ErrProfileNotFound = errors.New("profile not found")
+
 type UserRepository interface {
 	GetProfile(ctx context.Context, id string) (*models.Profile, error)
 	CreateProfile(ctx context.Context, profile *models.Profile) error
@@ -22,20 +27,31 @@ func NewUserRepository(client *supabase.Client) UserRepository {
 }
 
 func (r *userRepository) GetProfile(ctx context.Context, id string) (*models.Profile, error) {
-	var profile models.Profile
-	_, err := r.client.From("public.profiles").Select("*", "exact", false).Eq("id", id).Single().ExecuteTo(&profile)
+	data, _, err := r.client.From("profiles").
+		Select("*", "", false).
+		Eq("id", id).
+		Limit(1, "").
+		Execute()
 	if err != nil {
 		return nil, err
 	}
-	return &profile, nil
+
+	var profiles []models.Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, ErrProfileNotFound
+	}
+	return &profiles[0], nil
 }
 
 func (r *userRepository) CreateProfile(ctx context.Context, profile *models.Profile) error {
-	_, _, err := r.client.From("public.profiles").Insert(profile, false, "", "", "").Execute()
+	_, _, err := r.client.From("profiles").Insert(profile, false, "", "", "").Execute()
 	return err
 }
 
 func (r *userRepository) UpdateProfile(ctx context.Context, profile *models.Profile) error {
-	_, _, err := r.client.From("public.profiles").Update(profile, "", "exact").Eq("id", profile.ID).Execute()
+	_, _, err := r.client.From("profiles").Update(profile, "", "exact").Eq("id", profile.ID).Execute()
 	return err
 }
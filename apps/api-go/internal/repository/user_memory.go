@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dogab/vitalstack/api/internal/models"
+)
+
+// memoryUserRepository keeps the profiles in memory, e.g. for the mock API in dev mode
+type memoryUserRepository struct {
+	mu       sync.RWMutex
+	profiles map[string]models.Profile
+}
+
+// NewMemoryUserRepository creates an empty in-memory user repository
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{profiles: map[string]models.Profile{}}
+}
+
+func (r *memoryUserRepository) GetProfile(ctx context.Context, id string) (*models.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.profiles[id]
+	if !ok {
+		return nil, ErrProfileNotFound
+	}
+	return &profile, nil
+}
+
+func (r *memoryUserRepository) CreateProfile(ctx context.Context, profile *models.Profile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *profile
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	r.profiles[profile.ID] = stored
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateProfile(ctx context.Context, profile *models.Profile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.profiles[profile.ID]
+	if !ok {
+		return ErrProfileNotFound
+	}
+	createdAt := stored.CreatedAt
+	stored = *profile
+	stored.CreatedAt = createdAt
+	r.profiles[profile.ID] = stored
+	return nil
+}
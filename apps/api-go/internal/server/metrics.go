@@ -0,0 +1,76 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultMetricsPath is the path the Prometheus metrics are served on
+const DefaultMetricsPath = "/metrics"
+
+// httpMetrics records the number and latency of requests per operation
+type httpMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// WithMetrics registers the HTTP metrics with registry and serves all metrics of registry on path.
+// An empty path uses DefaultMetricsPath.
+func WithMetrics(registry *prometheus.Registry, path string) Option {
+	return func(s *Server) {
+		if path == "" {
+			path = DefaultMetricsPath
+		}
+		s.metricsRegistry = registry
+		s.metricsPath = path
+	}
+}
+
+// newHTTPMetrics creates the HTTP metrics and registers them with reg
+func newHTTPMetrics(reg prometheus.Registerer) *httpMetrics {
+	m := &httpMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "vitalstack",
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of requests by operation ID, method and status code",
+		}, []string{"operation", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "vitalstack",
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of requests by operation ID and method",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"operation", "method"}),
+	}
+	reg.MustRegister(m.requests, m.duration)
+	return m
+}
+
+// registerMetrics records the HTTP metrics of all operations and serves the metrics endpoint
+func (s *Server) registerMetrics(api huma.API) {
+	if s.metricsRegistry == nil {
+		return
+	}
+	metrics := newHTTPMetrics(s.metricsRegistry)
+	api.UseMiddleware(metrics.middleware)
+	s.router.GET(s.metricsPath, gin.WrapH(promhttp.HandlerFor(s.metricsRegistry, promhttp.HandlerOpts{
+		Registry: s.metricsRegistry,
+	})))
+}
+
+// middleware records the request of the operation once it is answered. It has to run before the
+// middlewares which may answer requests themselves, e.g. the body limit.
+func (m *httpMetrics) middleware(ctx huma.Context, next func(huma.Context)) {
+	start := time.Now()
+	next(ctx)
+
+	op := ctx.Operation()
+	m.requests.WithLabelValues(op.OperationID, op.Method, strconv.Itoa(ctx.Status())).Inc()
+	m.duration.WithLabelValues(op.OperationID, op.Method).Observe(time.Since(start).Seconds())
+}
@@ -2,15 +2,20 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humagin"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/dogab/vitalstack/api/internal/middleware"
 )
@@ -19,6 +24,8 @@ const (
 	DefaultReadHeaderTimeout = 30 * time.Second
 	// DefaultMaxMultipartMemory defines the maximum size of a multipart form request body (10MB)
 	DefaultMaxMultipartMemory = 10 << 20
+	// DefaultMaxBodyBytes defines the request body limit for operations without a route specific limit (1MB)
+	DefaultMaxBodyBytes = 1 << 20
 )
 
 // Controller is an interface for controllers
@@ -45,8 +52,16 @@ type Server struct {
 	huma huma.API
 
 	// Configuration
-	allowedOrigins []string
-	devMode        bool
+	allowedOrigins    []string
+	devMode           bool
+	maxBodyBytes      int64
+	routeMaxBodyBytes map[string]int64
+
+	// Metrics, disabled without a registry
+	metricsRegistry *prometheus.Registry
+	metricsPath     string
+
+	tracing bool // If true, a server span is started per request
 }
 
 // WithAllowedOrigins sets the allowed CORS origins
@@ -63,13 +78,35 @@ func WithDevMode(enabled bool) Option {
 	}
 }
 
+// WithMaxBodyBytes sets the default request body limit for all operations
+func WithMaxBodyBytes(limit int64) Option {
+	return func(s *Server) {
+		s.maxBodyBytes = limit
+	}
+}
+
+// WithRouteMaxBodyBytes sets request body limits per operation ID, overriding the default limit
+func WithRouteMaxBodyBytes(limits map[string]int64) Option {
+	return func(s *Server) {
+		s.routeMaxBodyBytes = limits
+	}
+}
+
+// WithTracing enables the server spans of requests, which are exported by the global tracer provider
+func WithTracing(enabled bool) Option {
+	return func(s *Server) {
+		s.tracing = enabled
+	}
+}
+
 // NewServer creates a new server instance
 func NewServer(addr string, opts ...Option) (*Server, ShutdownFunc) {
 	router := gin.New()
-	router.Use(gin.Recovery())
 
-	// Allow large request bodies for image uploads (10MB)
+	// Allow large request bodies for image uploads (10MB). Huma parses multipart
+	// forms itself, so the limit has to be applied to the adapter as well.
 	router.MaxMultipartMemory = DefaultMaxMultipartMemory
+	humagin.MultipartMaxMemory = DefaultMaxMultipartMemory
 
 	s := &Server{
 		srv: &http.Server{
@@ -82,13 +119,22 @@ func NewServer(addr string, opts ...Option) (*Server, ShutdownFunc) {
 		version:        "1.0.0",
 		allowedOrigins: []string{"http://localhost:3000"}, // Default
 		devMode:        false,
+		maxBodyBytes:   DefaultMaxBodyBytes,
 	}
 
-	// Apply options before configuring CORS
+	// Apply options before configuring the middleware and CORS
 	for _, opt := range opts {
 		opt(s)
 	}
 
+	// The request ID, access log and server span wrap the recovery, so that panics are logged and
+	// traced as 500 with their request
+	handlers := []gin.HandlerFunc{middleware.RequestIDMiddleware(), middleware.AccessLogMiddleware()}
+	if s.tracing {
+		handlers = append(handlers, middleware.TracingMiddleware())
+	}
+	router.Use(append(handlers, gin.Recovery())...)
+
 	// Configure CORS based on options
 	router.Use(cors.New(cors.Config{
 		AllowOrigins: s.allowedOrigins,
@@ -97,8 +143,8 @@ func NewServer(addr string, opts ...Option) (*Server, ShutdownFunc) {
 			return s.devMode
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "Accept-Units", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
@@ -106,7 +152,6 @@ func NewServer(addr string, opts ...Option) (*Server, ShutdownFunc) {
 	router.Use(middleware.DevAuthMiddleware(s.devMode))
 
 	s.huma = newHumaAPI(s)
-	s.registerDiagnosticEndpoints()
 
 	shutdownFunc := func(ctx context.Context) error {
 		slog.Info("VitalStack API shutting down...")
@@ -127,7 +172,66 @@ func newHumaAPI(s *Server) huma.API {
 			URL: "/",
 		},
 	}
-	return humagin.New(s.router, config)
+	api := humagin.New(s.router, config)
+
+	// The metrics middleware comes first, so that requests rejected by the body limit are recorded
+	s.registerMetrics(api)
+
+	// Apply body limits when operations are registered and reject oversized requests early
+	api.OpenAPI().OnAddOperation = append(api.OpenAPI().OnAddOperation, s.applyBodyLimit)
+	api.UseMiddleware(s.limitRequestBody(api))
+
+	return api
+}
+
+// applyBodyLimit sets the configured body limit on an operation as it is added to the API
+func (s *Server) applyBodyLimit(_ *huma.OpenAPI, op *huma.Operation) {
+	if limit, ok := s.routeMaxBodyBytes[op.OperationID]; ok {
+		op.MaxBodyBytes = limit
+		return
+	}
+	if s.maxBodyBytes > 0 {
+		op.MaxBodyBytes = s.maxBodyBytes
+	}
+}
+
+// limitRequestBody returns a middleware which rejects requests whose declared
+// Content-Length exceeds the operation body limit before the body is read,
+// and caps the body reader for requests which do not declare a length.
+// Huma only enforces MaxBodyBytes for JSON bodies, so this also covers multipart uploads.
+func (s *Server) limitRequestBody(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		limit := ctx.Operation().MaxBodyBytes
+		if limit <= 0 {
+			next(ctx)
+			return
+		}
+
+		tooLarge := func() {
+			_ = huma.WriteErr(api, ctx, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("request body is too large limit=%d bytes", limit))
+		}
+
+		if contentLength, err := strconv.ParseInt(ctx.Header("Content-Length"), 10, 64); err == nil && contentLength > limit {
+			tooLarge()
+			return
+		}
+
+		c := humagin.Unwrap(ctx)
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		// Multipart forms are parsed here, since Huma reports read errors of the form as 422. The parsed
+		// form is reused by Huma, other parse errors are left to Huma's validation.
+		if strings.HasPrefix(ctx.Header("Content-Type"), "multipart/form-data") {
+			var maxBytesErr *http.MaxBytesError
+			if err := c.Request.ParseMultipartForm(humagin.MultipartMaxMemory); errors.As(err, &maxBytesErr) {
+				tooLarge()
+				return
+			}
+		}
+
+		next(ctx)
+	}
 }
 
 // RegisterAPI registers the API with all provided controllers
@@ -148,13 +252,3 @@ func (s *Server) Serve(ctx context.Context) error {
 
 	return nil
 }
-
-func (s *Server) registerDiagnosticEndpoints() {
-	s.router.GET("/api/health", s.healthCheckHandler)
-}
-
-func (s *Server) healthCheckHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-	})
-}
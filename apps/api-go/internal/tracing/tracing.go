@@ -0,0 +1,113 @@
+// Package tracing sets up the OpenTelemetry tracer provider of the API. The provider is installed
+// globally, so that the spans of the server, the services, the repositories and the Genkit flows
+// share it and Genkit spans are nested under the request span.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.32.0"
+)
+
+// Exporter selects where spans are sent
+type Exporter string
+
+const (
+	// ExporterNone disables tracing
+	ExporterNone Exporter = "none"
+	// ExporterOTLP sends spans to an OTLP/HTTP collector, e.g. Jaeger, Tempo or the OpenTelemetry Collector
+	ExporterOTLP Exporter = "otlp"
+	// ExporterStdout writes spans as JSON to stdout or a file, e.g. for offline development
+	ExporterStdout Exporter = "stdout"
+)
+
+// Exporters lists all supported exporters
+var Exporters = []Exporter{ExporterNone, ExporterOTLP, ExporterStdout}
+
+// ServiceName is the service name of the spans of the API
+const ServiceName = "vitalstack-api"
+
+// Config describes how spans are exported
+type Config struct {
+	Exporter Exporter
+	// Endpoint is the URL of the OTLP/HTTP collector, e.g. "http://localhost:4318". If empty, the
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable or localhost:4318 is used.
+	Endpoint string
+	// File is the path the stdout exporter appends spans to, empty writes to stdout
+	File string
+	// SampleRatio is the fraction of traces recorded, sampled parent spans are always followed
+	SampleRatio float64
+}
+
+// ShutdownFunc flushes the remaining spans and stops the exporter
+type ShutdownFunc func(context.Context) error
+
+// Init creates the tracer provider of the configured exporter and installs it together with the
+// W3C trace context propagator as the global provider. With ExporterNone nothing is installed.
+func Init(ctx context.Context, cfg Config) (ShutdownFunc, error) {
+	var exporter sdktrace.SpanExporter
+	var file io.Closer
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		var err error
+		if exporter, err = otlptracehttp.New(ctx, opts...); err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+	case ExporterStdout:
+		var w io.Writer = os.Stdout
+		if cfg.File != "" {
+			//nolint:gosec // the path is configured by the operator
+			f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open trace file: %w", err)
+			}
+			w, file = f, f
+		}
+		var err error
+		if exporter, err = stdouttrace.New(stdouttrace.WithWriter(w)); err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q, expected one of %v", cfg.Exporter, Exporters)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	slog.Info("initialized tracing", "exporter", cfg.Exporter, "endpoint", cfg.Endpoint, "file", cfg.File, "sample_ratio", cfg.SampleRatio)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			err = errors.Join(err, file.Close())
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,15 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a key value store for serialized values. It is byte oriented so that
+// remote stores such as Redis can implement it without knowing the cached types.
+type Cache interface {
+	// Get returns the value stored for key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value for key. A ttl of 0 uses the default expiry of the cache.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
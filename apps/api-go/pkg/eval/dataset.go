@@ -0,0 +1,112 @@
+package eval
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// imageExtensions lists the image file extensions looked up for a label file
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".webp", ".heic", ".heif", ".gif"}
+
+// Label is the ground truth of a dataset case, stored as <case>.json next to the image
+type Label struct {
+	IsFood      bool                 `json:"is_food"`
+	Description *string              `json:"description,omitempty"`
+	Ingredients []service.Ingredient `json:"ingredients,omitempty"`
+}
+
+// Macros returns the ground truth macros summed over all ingredients
+func (l *Label) Macros() service.MacroData {
+	output := service.ScanOutput{Ingredients: l.Ingredients}
+	return output.TotalMacros()
+}
+
+// Case is a labeled image of the dataset
+type Case struct {
+	// Name is the path of the case relative to the dataset directory without extension
+	Name      string
+	ImagePath string
+	Label     Label
+}
+
+// LoadDataset reads all cases of the dataset directory. Every label file <case>.json must have an
+// image with the same name (e.g. <case>.jpg). Subdirectories are searched recursively.
+func LoadDataset(dir string) ([]Case, error) {
+	var cases []Case
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		c, err := loadCase(dir, path)
+		if err != nil {
+			return err
+		}
+		cases = append(cases, *c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dataset %q: %w", dir, err)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("dataset %q contains no labeled images", dir)
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// loadCase reads the label file at labelPath and locates its image
+func loadCase(dir, labelPath string) (*Case, error) {
+	data, err := os.ReadFile(labelPath) //nolint:gosec // the dataset directory is provided by the operator
+	if err != nil {
+		return nil, err
+	}
+
+	var label Label
+	if err := json.Unmarshal(data, &label); err != nil {
+		return nil, fmt.Errorf("invalid label file %q: %w", labelPath, err)
+	}
+
+	base := strings.TrimSuffix(labelPath, ".json")
+	name, err := filepath.Rel(dir, base)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ext := range imageExtensions {
+		for _, candidate := range []string{base + ext, base + strings.ToUpper(ext)} {
+			if _, err := os.Stat(candidate); err == nil {
+				return &Case{Name: filepath.ToSlash(name), ImagePath: candidate, Label: label}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no image found for label file %q", labelPath)
+}
+
+// ScanInput reads the image of the case and builds the scan input
+func (c *Case) ScanInput() (*service.ScanInput, error) {
+	image, err := os.ReadFile(c.ImagePath) //nolint:gosec // the dataset directory is provided by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image of case %q: %w", c.Name, err)
+	}
+
+	return &service.ScanInput{
+		ImageBase64:   base64.StdEncoding.EncodeToString(image),
+		ImageMimeType: mime.TypeByExtension(strings.ToLower(filepath.Ext(c.ImagePath))),
+		Description:   c.Label.Description,
+	}, nil
+}
@@ -0,0 +1,94 @@
+// Package eval measures the accuracy of the food scan against a labeled dataset
+package eval
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// Outcome is the food detection and the total macros of a case, as labeled or predicted
+type Outcome struct {
+	IsFood bool              `json:"is_food"`
+	Macros service.MacroData `json:"macros"`
+}
+
+// IngredientResult holds the ingredient matching of a case
+type IngredientResult struct {
+	Labeled   int `json:"labeled"`
+	Predicted int `json:"predicted"`
+	Matched   int `json:"matched"`
+	// Missed are the labeled ingredients without a matching prediction
+	Missed []string `json:"missed,omitempty"`
+	// Extra are the predicted ingredients without a matching label
+	Extra []string `json:"extra,omitempty"`
+}
+
+// CaseResult is the outcome of a single dataset case
+type CaseResult struct {
+	Name          string           `json:"name"`
+	Expected      Outcome          `json:"expected"`
+	Predicted     Outcome          `json:"predicted"`
+	Ingredients   IngredientResult `json:"ingredients"`
+	Model         string           `json:"model,omitempty"`
+	PromptVersion string           `json:"prompt_version,omitempty"`
+	Duration      time.Duration    `json:"duration_ns"`
+	// Error is set if the case could not be scanned. Failed cases are excluded from all metrics.
+	Error string `json:"error,omitempty"`
+}
+
+// Run scans all cases with up to concurrency scans in parallel and evaluates the results
+func Run(ctx context.Context, scanner Scanner, cases []Case, concurrency int) *Report {
+	results := make([]CaseResult, len(cases))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, c := range cases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runCase(ctx, scanner, c)
+		}()
+	}
+	wg.Wait()
+
+	return newReport(results)
+}
+
+// runCase scans a single case and compares the result with its label
+func runCase(ctx context.Context, scanner Scanner, c Case) CaseResult {
+	result := CaseResult{
+		Name:     c.Name,
+		Expected: Outcome{IsFood: c.Label.IsFood, Macros: c.Label.Macros()},
+	}
+
+	start := time.Now()
+	output, err := scanner.Scan(ctx, c)
+	result.Duration = time.Since(start)
+	if err != nil {
+		slog.Warn("failed to scan evaluation case", "case", c.Name, "error", err)
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Predicted = Outcome{IsFood: output.IsFood, Macros: output.TotalMacros()}
+	result.Model = output.Model
+	result.PromptVersion = output.PromptVersion
+
+	matched, missed, extra := matchIngredients(c.Label.Ingredients, output.Ingredients)
+	result.Ingredients = IngredientResult{
+		Labeled:   len(c.Label.Ingredients),
+		Predicted: len(output.Ingredients),
+		Matched:   matched,
+		Missed:    missed,
+		Extra:     extra,
+	}
+
+	slog.Debug("evaluated case", "case", c.Name, "duration", result.Duration, "output", output)
+	return result
+}
@@ -0,0 +1,199 @@
+package eval
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// ingredientMatchThreshold is the minimum name similarity for a predicted ingredient to match a labeled one
+const ingredientMatchThreshold = 0.5
+
+// Macro names in report order
+const (
+	MacroCalories = "calories"
+	MacroProtein  = "protein"
+	MacroCarbs    = "carbs"
+	MacroFat      = "fat"
+	MacroFiber    = "fiber"
+)
+
+// macroNames lists the evaluated macros in report order
+var macroNames = []string{MacroCalories, MacroProtein, MacroCarbs, MacroFat, MacroFiber}
+
+// macroValues returns the macros keyed by name
+func macroValues(m service.MacroData) map[string]float64 {
+	return map[string]float64{
+		MacroCalories: float64(m.Calories),
+		MacroProtein:  m.Protein,
+		MacroCarbs:    m.Carbs,
+		MacroFat:      m.Fat,
+		MacroFiber:    m.Fiber,
+	}
+}
+
+// MacroError holds the error of a macro over all food cases
+type MacroError struct {
+	// MAE is the mean absolute error in the unit of the macro (kcal or grams)
+	MAE float64 `json:"mae"`
+	// MAPE is the mean absolute percentage error over the cases with a labeled value above zero
+	MAPE float64 `json:"mape"`
+	// Cases is the number of cases the MAE is computed over
+	Cases int `json:"cases"`
+}
+
+// Detection holds the counts and scores of the non-food detection. Images without food are the positive class.
+type Detection struct {
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	TrueNegatives  int     `json:"true_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+}
+
+// IngredientMatch holds the micro averaged ingredient matching scores over all cases
+type IngredientMatch struct {
+	Matched   int     `json:"matched"`
+	Predicted int     `json:"predicted"`
+	Labeled   int     `json:"labeled"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// macroErrors computes the MAE and MAPE of every macro over the cases where both the label
+// and the prediction contain food
+func macroErrors(results []CaseResult) map[string]MacroError {
+	type sums struct {
+		absolute, percentage float64
+		cases, percentCases  int
+	}
+	totals := map[string]*sums{}
+	for _, name := range macroNames {
+		totals[name] = &sums{}
+	}
+
+	for _, r := range results {
+		if r.Error != "" || !r.Expected.IsFood || !r.Predicted.IsFood {
+			continue
+		}
+		expected, predicted := macroValues(r.Expected.Macros), macroValues(r.Predicted.Macros)
+		for _, name := range macroNames {
+			diff := math.Abs(predicted[name] - expected[name])
+			t := totals[name]
+			t.absolute += diff
+			t.cases++
+			if expected[name] > 0 {
+				t.percentage += diff / expected[name] * 100
+				t.percentCases++
+			}
+		}
+	}
+
+	errs := make(map[string]MacroError, len(macroNames))
+	for _, name := range macroNames {
+		t := totals[name]
+		errs[name] = MacroError{
+			MAE:   round(ratio(t.absolute, float64(t.cases))),
+			MAPE:  round(ratio(t.percentage, float64(t.percentCases))),
+			Cases: t.cases,
+		}
+	}
+	return errs
+}
+
+// detection computes the non-food detection scores
+func detection(results []CaseResult) Detection {
+	var d Detection
+	for _, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		switch expectedNonFood, predictedNonFood := !r.Expected.IsFood, !r.Predicted.IsFood; {
+		case expectedNonFood && predictedNonFood:
+			d.TruePositives++
+		case !expectedNonFood && predictedNonFood:
+			d.FalsePositives++
+		case expectedNonFood && !predictedNonFood:
+			d.FalseNegatives++
+		default:
+			d.TrueNegatives++
+		}
+	}
+	d.Precision = round(ratio(float64(d.TruePositives), float64(d.TruePositives+d.FalsePositives)))
+	d.Recall = round(ratio(float64(d.TruePositives), float64(d.TruePositives+d.FalseNegatives)))
+	return d
+}
+
+// ingredientMatch computes the ingredient matching scores over all cases
+func ingredientMatch(results []CaseResult) IngredientMatch {
+	var m IngredientMatch
+	for _, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		m.Matched += r.Ingredients.Matched
+		m.Predicted += r.Ingredients.Predicted
+		m.Labeled += r.Ingredients.Labeled
+	}
+	m.Precision = ratio(float64(m.Matched), float64(m.Predicted))
+	m.Recall = ratio(float64(m.Matched), float64(m.Labeled))
+	m.F1 = round(ratio(2*m.Precision*m.Recall, m.Precision+m.Recall))
+	m.Precision, m.Recall = round(m.Precision), round(m.Recall)
+	return m
+}
+
+// matchIngredients greedily pairs the predicted with the labeled ingredients by name similarity,
+// most similar pairs first. It returns the number of matches and the names of the missed and extra ingredients.
+func matchIngredients(labeled, predicted []service.Ingredient) (matched int, missed, extra []string) {
+	type pair struct {
+		l, p       int
+		similarity float64
+	}
+	var pairs []pair
+	for l := range labeled {
+		for p := range predicted {
+			if s := service.NameSimilarity(labeled[l].Name, predicted[p].Name); s >= ingredientMatchThreshold {
+				pairs = append(pairs, pair{l: l, p: p, similarity: s})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].similarity > pairs[j].similarity })
+
+	usedLabeled := make([]bool, len(labeled))
+	usedPredicted := make([]bool, len(predicted))
+	for _, pair := range pairs {
+		if usedLabeled[pair.l] || usedPredicted[pair.p] {
+			continue
+		}
+		usedLabeled[pair.l], usedPredicted[pair.p] = true, true
+		matched++
+	}
+
+	for l, used := range usedLabeled {
+		if !used {
+			missed = append(missed, labeled[l].Name)
+		}
+	}
+	for p, used := range usedPredicted {
+		if !used {
+			extra = append(extra, predicted[p].Name)
+		}
+	}
+	return matched, missed, extra
+}
+
+// ratio divides a by b and returns 0 if b is 0
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
+
+// round rounds the value to 3 decimal places for readable reports
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,65 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Report holds the aggregated metrics and the results of every case of an evaluation run
+type Report struct {
+	Cases  int `json:"cases"`
+	Failed int `json:"failed"`
+	// Macros holds the error of the total macros per macro name
+	Macros map[string]MacroError `json:"macros"`
+	// NonFood holds the detection scores of images without food
+	NonFood     Detection       `json:"non_food"`
+	Ingredients IngredientMatch `json:"ingredients"`
+	Results     []CaseResult    `json:"results"`
+}
+
+// newReport aggregates the case results into a report
+func newReport(results []CaseResult) *Report {
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	return &Report{
+		Cases:       len(results),
+		Failed:      failed,
+		Macros:      macroErrors(results),
+		NonFood:     detection(results),
+		Ingredients: ingredientMatch(results),
+		Results:     results,
+	}
+}
+
+// WriteTable writes the report as human readable console tables
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "CASE\tFOOD\tPREDICTED\tKCAL\tPREDICTED\tMATCHED\tMISSED\tEXTRA\tERROR\n")
+	for _, c := range r.Results {
+		fmt.Fprintf(tw, "%s\t%t\t%t\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			c.Name, c.Expected.IsFood, c.Predicted.IsFood,
+			c.Expected.Macros.Calories, c.Predicted.Macros.Calories,
+			c.Ingredients.Matched, len(c.Ingredients.Missed), len(c.Ingredients.Extra), c.Error)
+	}
+
+	fmt.Fprintf(tw, "\nMACRO\tMAE\tMAPE %%\tCASES\n")
+	for _, name := range macroNames {
+		m := r.Macros[name]
+		fmt.Fprintf(tw, "%s\t%.2f\t%.1f\t%d\n", name, m.MAE, m.MAPE, m.Cases)
+	}
+
+	fmt.Fprintf(tw, "\nMETRIC\tPRECISION\tRECALL\tF1\n")
+	fmt.Fprintf(tw, "non-food detection\t%.3f\t%.3f\t-\n", r.NonFood.Precision, r.NonFood.Recall)
+	fmt.Fprintf(tw, "ingredient match\t%.3f\t%.3f\t%.3f\n", r.Ingredients.Precision, r.Ingredients.Recall, r.Ingredients.F1)
+
+	fmt.Fprintf(tw, "\ncases: %d, failed: %d\n", r.Cases, r.Failed)
+
+	return tw.Flush()
+}
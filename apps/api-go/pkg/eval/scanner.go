@@ -0,0 +1,61 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// Scanner produces the scan result for a dataset case
+type Scanner interface {
+	Scan(ctx context.Context, c Case) (*service.ScanOutput, error)
+}
+
+// ServiceScanner runs the cases through the food scan flow of the nutrition service
+type ServiceScanner struct {
+	svc *service.NutritionService
+}
+
+// NewServiceScanner creates a scanner backed by the food scan flow
+func NewServiceScanner(svc *service.NutritionService) *ServiceScanner {
+	return &ServiceScanner{svc: svc}
+}
+
+// Scan implements Scanner
+func (s *ServiceScanner) Scan(ctx context.Context, c Case) (*service.ScanOutput, error) {
+	input, err := c.ScanInput()
+	if err != nil {
+		return nil, err
+	}
+	return s.svc.AnalyzeFood(ctx, input)
+}
+
+// RecordedScanner replays recorded scan results instead of calling the model. The response of
+// a case is read from <dir>/<case>.json and holds the scan output as returned by the flow.
+type RecordedScanner struct {
+	dir string
+}
+
+// NewRecordedScanner creates a scanner replaying the responses recorded in dir
+func NewRecordedScanner(dir string) *RecordedScanner {
+	return &RecordedScanner{dir: dir}
+}
+
+// Scan implements Scanner
+func (s *RecordedScanner) Scan(_ context.Context, c Case) (*service.ScanOutput, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(c.Name)+".json")
+	data, err := os.ReadFile(path) //nolint:gosec // the responses directory is provided by the operator
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for case %q: %w", c.Name, err)
+	}
+
+	var output service.ScanOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("invalid recorded response %q: %w", path, err)
+	}
+	return &output, nil
+}
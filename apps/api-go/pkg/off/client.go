@@ -0,0 +1,74 @@
+package off
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// userAgent identifies the API, Open Food Facts asks clients to send a descriptive user agent
+const userAgent = "vitalstack-api/1.0 (https://github.com/dogab/vitalstack)"
+
+// Client looks up products through the Open Food Facts product API. The base URL can point to a
+// mirror or a local stand-in serving /api/v2/product/{barcode}.json.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the product API at baseURL, e.g. https://world.openfoodfacts.org
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// productResponse is the response of the product API, status is 0 for unknown products
+type productResponse struct {
+	Status  int      `json:"status"`
+	Product *product `json:"product"`
+}
+
+// LookupProduct implements service.ProductLookup. Unknown products and products without a name or
+// energy return nil.
+func (c *Client) LookupProduct(ctx context.Context, barcode string) (*service.Food, error) {
+	u := fmt.Sprintf("%s/api/v2/product/%s.json?fields=%s", c.baseURL, url.PathEscape(barcode), strings.Join(productFields, ","))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read only
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product lookup answered with status %d", resp.StatusCode)
+	}
+
+	var body productResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid product lookup response: %w", err)
+	}
+	if body.Status != 1 || body.Product == nil {
+		return nil, nil
+	}
+	if body.Product.Code == "" {
+		body.Product.Code = barcode
+	}
+	return newFood(body.Product), nil
+}
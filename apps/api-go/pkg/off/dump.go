@@ -0,0 +1,56 @@
+package off
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Read streams the products of the JSONL product dump at path, one product per line. Gzipped dumps
+// (.gz) are decompressed while reading, since the full dump is tens of gigabytes uncompressed.
+func Read(path string, fn FoodFunc) error {
+	f, err := os.Open(path) //nolint:gosec // the dump is provided by the operator
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read only
+
+	var r io.Reader = f
+	if strings.EqualFold(filepath.Ext(path), ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("invalid Open Food Facts dump %q: %w", path, err)
+		}
+		defer gz.Close() //nolint:errcheck // read only
+		r = gz
+	}
+
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			var p product
+			if err := json.Unmarshal(data, &p); err != nil {
+				return fmt.Errorf("invalid Open Food Facts dump %q: line %d: %w", path, line, err)
+			}
+			if food := newFood(&p); food != nil {
+				if err := fn(food); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid Open Food Facts dump %q: %w", path, err)
+		}
+	}
+}
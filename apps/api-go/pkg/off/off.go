@@ -0,0 +1,179 @@
+// Package off reads packaged products of Open Food Facts (https://world.openfoodfacts.org) into reference foods.
+//
+// Products are read from the JSONL product dump (openfoodfacts-products.jsonl.gz) for offline imports, or
+// fetched one by one through the product API by Client. The nutriments of Open Food Facts are per 100 g
+// (per 100 ml for beverages) and in grams, they are converted to the units of the nutrient catalog.
+package off
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// nutrientKeys maps the IDs of the nutrient catalog to the Open Food Facts nutriments and the factor
+// converting grams to the unit of the catalog
+var nutrientKeys = map[string]struct {
+	key    string
+	factor float64
+}{
+	"sugar":         {"sugars", 1},
+	"saturated_fat": {"saturated-fat", 1},
+	"sodium":        {"sodium", 1e3},
+	"cholesterol":   {"cholesterol", 1e3},
+	"potassium":     {"potassium", 1e3},
+	"calcium":       {"calcium", 1e3},
+	"iron":          {"iron", 1e3},
+	"vitamin_a":     {"vitamin-a", 1e6},
+	"vitamin_c":     {"vitamin-c", 1e3},
+	"vitamin_d":     {"vitamin-d", 1e6},
+	"vitamin_b12":   {"vitamin-b12", 1e6},
+}
+
+const (
+	// maxCalories per 100 g is pure fat, products declaring more have broken nutriments
+	maxCalories = 900
+	// maxServingGrams is the largest plausible serving, larger values are typos
+	maxServingGrams = 10000
+)
+
+// productFields are the fields of a product which are read, the API only returns these
+var productFields = []string{
+	"code", "product_name", "product_name_en", "generic_name", "brands", "categories",
+	"serving_size", "serving_quantity", "serving_quantity_unit", "nutriments",
+}
+
+// product is a product of the dump and the product API. Numbers are strings in some products.
+type product struct {
+	Code                string         `json:"code"`
+	ProductName         string         `json:"product_name"`
+	ProductNameEN       string         `json:"product_name_en"`
+	GenericName         string         `json:"generic_name"`
+	Brands              string         `json:"brands"`
+	Categories          string         `json:"categories"`
+	ServingSize         string         `json:"serving_size"`
+	ServingQuantity     any            `json:"serving_quantity"`
+	ServingQuantityUnit string         `json:"serving_quantity_unit"`
+	Nutriments          map[string]any `json:"nutriments"`
+}
+
+// FoodFunc receives every product read from a dump. Returning an error stops the reading.
+type FoodFunc func(food *service.Food) error
+
+// newFood creates the food from the product. Products without a valid barcode, name or energy are
+// skipped (nil), they can neither be found nor used to compute macros. So are products with
+// implausible nutriments, e.g. entered per package instead of per 100 g.
+func newFood(p *product) *service.Food {
+	barcode, ok := service.NormalizeBarcode(p.Code)
+	if !ok {
+		return nil
+	}
+	name := strings.TrimSpace(firstNonEmpty(p.ProductName, p.ProductNameEN, p.GenericName))
+	if name == "" {
+		return nil
+	}
+	calories, ok := p.nutriment("energy-kcal")
+	if !ok {
+		// Energy in kJ
+		kilojoules, ok := p.nutriment("energy")
+		if !ok {
+			return nil
+		}
+		calories = kilojoules / 4.184
+	}
+
+	protein, _ := p.nutriment("proteins")
+	carbs, _ := p.nutriment("carbohydrates")
+	fat, _ := p.nutriment("fat")
+	fiber, _ := p.nutriment("fiber")
+	macros := service.MacroData{
+		Calories: int(math.Round(calories)),
+		Protein:  protein,
+		Carbs:    carbs,
+		Fat:      fat,
+		Fiber:    fiber,
+	}
+	if !plausible(macros) {
+		return nil
+	}
+	for id, n := range nutrientKeys {
+		if amount, ok := p.nutriment(n.key); ok {
+			if macros.Nutrients == nil {
+				macros.Nutrients = service.NutrientValues{}
+			}
+			macros.Nutrients[id] = amount * n.factor
+		}
+	}
+	// Labels in the EU declare salt instead of sodium
+	if _, ok := macros.Nutrients["sodium"]; !ok {
+		if salt, ok := p.nutriment("salt"); ok {
+			if macros.Nutrients == nil {
+				macros.Nutrients = service.NutrientValues{}
+			}
+			macros.Nutrients["sodium"] = salt / 2.5 * 1e3
+		}
+	}
+
+	food := &service.Food{
+		Source:   service.FoodSourceOpenFoodFacts,
+		SourceID: barcode,
+		Name:     name,
+		Category: firstItem(p.Categories),
+		Per100g:  macros,
+		Barcode:  barcode,
+		Brand:    firstItem(p.Brands),
+	}
+	if grams, ok := number(p.ServingQuantity); ok && grams > 0 && grams <= maxServingGrams {
+		food.Serving = &service.Serving{Size: strings.TrimSpace(p.ServingSize), Grams: grams, Unit: "g"}
+		if unit := strings.ToLower(p.ServingQuantityUnit); unit == "ml" {
+			food.Serving.Unit = unit
+		}
+	}
+	return food
+}
+
+// plausible reports whether the macros can be per 100 g: at most the energy of pure fat and at most
+// 100 g of protein, carbs and fat together
+func plausible(m service.MacroData) bool {
+	return m.Calories <= maxCalories && m.Protein+m.Carbs+m.Fat <= 100 && m.Fiber <= 100
+}
+
+// nutriment returns the amount per 100 g of the nutriment
+func (p *product) nutriment(key string) (float64, bool) {
+	amount, ok := number(p.Nutriments[key+"_100g"])
+	return amount, ok && amount >= 0
+}
+
+// number returns the value of a JSON number or numeric string
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// firstItem returns the first item of a comma separated list, e.g. the main brand
+func firstItem(list string) string {
+	item, _, _ := strings.Cut(list, ",")
+	return strings.TrimSpace(item)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/dogab/vitalstack/api/internal/repository"
+	"github.com/dogab/vitalstack/api/pkg/types"
+)
+
+const (
+	// FoodSourceOpenFoodFacts are packaged products of Open Food Facts, the source ID is the barcode
+	FoodSourceOpenFoodFacts FoodSource = "off"
+)
+
+// Serving is the serving size printed on the label of a packaged product
+type Serving struct {
+	// Size is the serving as printed on the label, e.g. "1 bar (40 g)"
+	Size string `json:"size,omitempty"`
+	// Grams is the weight of a serving, or its volume in ml if the unit is ml
+	Grams float64 `json:"grams"`
+	Unit  string  `json:"unit"`
+}
+
+// ProductLookup looks up packaged products missing in the food database, e.g. through the Open Food Facts API
+type ProductLookup interface {
+	// LookupProduct returns the product with the normalized barcode, or nil if the product is unknown
+	LookupProduct(ctx context.Context, barcode string) (*Food, error)
+}
+
+// FoodLogger logs meals, it is implemented by NutritionService
+type FoodLogger interface {
+	LogFood(ctx context.Context, input *LogFoodInput) (*LogFoodOutput, error)
+}
+
+// WithProductLookup looks up products which are not in the food database with lookup. Found
+// products are added to the food database, so every product is only looked up once.
+func WithProductLookup(lookup ProductLookup) FoodServiceOption {
+	return func(s *FoodService) {
+		s.productLookup = lookup
+	}
+}
+
+// WithFoodLogger logs packaged products with logger
+func WithFoodLogger(logger FoodLogger) FoodServiceOption {
+	return func(s *FoodService) {
+		s.foodLogger = logger
+	}
+}
+
+// LogProductInput represents the request to log a packaged product
+type LogProductInput struct {
+	UserID  *string
+	Barcode string
+	// Servings is the number of label servings eaten, Grams overrides it if set
+	Servings float64
+	Grams    float64
+}
+
+// NormalizeBarcode validates an EAN-8, UPC-A, EAN-13 or GTIN-14 barcode and returns it in the
+// form products are stored with: UPC-A codes get a leading zero (EAN-13), GTIN-14 codes with a
+// leading zero lose it
+func NormalizeBarcode(code string) (string, bool) {
+	code = strings.TrimSpace(code)
+	switch {
+	case len(code) == 12:
+		code = "0" + code
+	case len(code) == 14 && code[0] == '0':
+		code = code[1:]
+	}
+	if len(code) != 8 && len(code) != 13 && len(code) != 14 {
+		return "", false
+	}
+
+	// GTIN check digit: the digits are weighted 3 and 1 alternately from the right
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		if code[i] < '0' || code[i] > '9' {
+			return "", false
+		}
+		if i == len(code)-1 {
+			continue
+		}
+		digit := int(code[i] - '0')
+		if (len(code)-1-i)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	if (10-sum%10)%10 != int(code[len(code)-1]-'0') {
+		return "", false
+	}
+	return code, true
+}
+
+// GetFoodByBarcode returns the packaged product with the barcode. Products which are not in the food
+// database are looked up if a ProductLookup is configured.
+func (s *FoodService) GetFoodByBarcode(ctx context.Context, code string) (*Food, error) {
+	if s.foodRepo == nil {
+		return nil, errors.New("food repository is not configured")
+	}
+	barcode, ok := NormalizeBarcode(code)
+	if !ok {
+		return nil, types.NewValidationError("invalid EAN/UPC barcode", "ean", "path", code)
+	}
+
+	dbFood, err := s.foodRepo.GetFoodByBarcode(ctx, barcode)
+	if err == nil {
+		food := newFood(dbFood)
+		return &food, nil
+	}
+	if !errors.Is(err, repository.ErrFoodNotFound) {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if s.productLookup == nil {
+		return nil, types.NewNotFoundError(fmt.Sprintf("product %s not found", barcode))
+	}
+
+	food, err := s.productLookup.LookupProduct(ctx, barcode)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to look up product", "barcode", barcode, "error", err)
+		return nil, types.NewBadGatewayError("product lookup failed")
+	}
+	if food == nil {
+		return nil, types.NewNotFoundError(fmt.Sprintf("product %s not found", barcode))
+	}
+
+	// Store the product, so that it is found in the database next time and logs can reference it
+	if err := s.ImportFoods(ctx, []Food{*food}); err != nil {
+		slog.WarnContext(ctx, "Failed to store looked up product", "barcode", barcode, "error", err)
+		return food, nil
+	}
+	if dbFood, err := s.foodRepo.GetFoodByBarcode(ctx, barcode); err == nil {
+		food.ID = dbFood.ID
+	}
+	return food, nil
+}
+
+// LogProduct logs the packaged product with the barcode as a meal of a single ingredient
+func (s *FoodService) LogProduct(ctx context.Context, input *LogProductInput) (*LogFoodOutput, error) {
+	if s.foodLogger == nil {
+		return nil, errors.New("food logger is not configured")
+	}
+	if input.Servings < 0 {
+		return nil, types.NewValidationError("servings must not be negative", "servings", "body", input.Servings)
+	}
+	if input.Grams < 0 {
+		return nil, types.NewValidationError("grams must not be negative", "grams", "body", input.Grams)
+	}
+
+	food, err := s.GetFoodByBarcode(ctx, input.Barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	grams := input.Grams
+	if grams == 0 {
+		if food.Serving == nil {
+			return nil, types.NewValidationError("the product has no serving size, log grams instead", "grams", "body", input.Grams)
+		}
+		servings := input.Servings
+		if servings == 0 {
+			servings = 1
+		}
+		grams = servings * food.Serving.Grams
+	}
+
+	ing := food.Ingredient(grams)
+	return s.foodLogger.LogFood(ctx, &LogFoodInput{
+		UserID:      input.UserID,
+		FoodName:    ing.Name,
+		Confidence:  1,
+		Macros:      (&ScanOutput{Ingredients: []Ingredient{ing}}).TotalMacros(),
+		Ingredients: []Ingredient{ing},
+	})
+}
+
+// Ingredient maps the food to an ingredient of the given weight. Servings are counted in label
+// servings for packaged products and in grams otherwise.
+func (f *Food) Ingredient(grams float64) Ingredient {
+	name := f.Name
+	if f.Brand != "" && !strings.Contains(strings.ToLower(name), strings.ToLower(f.Brand)) {
+		name = fmt.Sprintf("%s (%s)", name, f.Brand)
+	}
+
+	servingSize, servingQuantity, servingUnit := int(math.Round(grams)), 1.0, "g"
+	if f.Serving != nil && f.Serving.Grams > 0 {
+		servingSize = int(math.Round(f.Serving.Grams))
+		servingQuantity = math.Round(grams/f.Serving.Grams*100) / 100
+		servingUnit = f.Serving.Unit
+	}
+
+	source := IngredientSourceDatabase
+	if f.Source == FoodSourceLabel {
+		source = IngredientSourceLabel
+	}
+
+	macros := f.Per100g.scaled(grams / 100)
+	return Ingredient{
+		Name:            name,
+		ServingSize:     &servingSize,
+		ServingQuantity: &servingQuantity,
+		ServingUnit:     &servingUnit,
+		Calories:        macros.Calories,
+		Protein:         macros.Protein,
+		Carbs:           macros.Carbs,
+		Fat:             macros.Fat,
+		Fiber:           macros.Fiber,
+		Nutrients:       macros.Nutrients.Amounts(),
+		// The label values are exact, only the amount eaten is estimated by the user
+		Confidence: 1,
+		Grams:      grams,
+		GramsLow:   grams,
+		GramsHigh:  grams,
+		Source:     source,
+		FoodID:     f.ID,
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestNormalizeBarcode(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{name: "EAN-13", code: "3017620422003", want: "3017620422003", wantOK: true},
+		{name: "EAN-8", code: "96385074", want: "96385074", wantOK: true},
+		{name: "UPC-A gets a leading zero", code: "049000028911", want: "0049000028911", wantOK: true},
+		{name: "GTIN-14 with leading zero", code: "03017620422003", want: "3017620422003", wantOK: true},
+		{name: "GTIN-14", code: "10012345678902", want: "10012345678902", wantOK: true},
+		{name: "surrounding whitespace", code: " 3017620422003\n", want: "3017620422003", wantOK: true},
+		{name: "wrong check digit", code: "3017620422004"},
+		{name: "letters", code: "30176204220a3"},
+		{name: "too short", code: "1234567"},
+		{name: "too long", code: "123456789012345"},
+		{name: "empty", code: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeBarcode(tt.code)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizeBarcode(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
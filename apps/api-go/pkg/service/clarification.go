@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// DefaultClarificationThreshold is the confidence below which scans ask clarifying questions
+const DefaultClarificationThreshold = 0.6
+
+// ClarificationAnswer is the answer of the user to a clarifying question of a scan
+type ClarificationAnswer struct {
+	Question string
+	Answer   string
+}
+
+// WithClarificationThreshold sets the confidence below which the clarifying questions and alternative
+// dishes of the model are returned. A threshold of 0 never asks for clarification.
+func WithClarificationThreshold(threshold float64) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.clarificationThreshold = threshold
+	}
+}
+
+// ClarifyScan scans the food again with the answers to the clarifying questions appended to the description
+func (s *NutritionService) ClarifyScan(ctx context.Context, input *ScanInput, answers []ClarificationAnswer) (*ScanOutput, error) {
+	return traced(ctx, "NutritionService.ClarifyScan", func(ctx context.Context) (*ScanOutput, error) {
+		input.Description = clarifiedDescription(input.Description, answers)
+		slog.InfoContext(ctx, "received food scan clarification", "input", input, "answers", len(answers))
+		return rejectNonFood(s.scanFood(ctx, input, nil))
+	})
+}
+
+// clarifiedDescription appends the answered questions to the meal description
+func clarifiedDescription(description *string, answers []ClarificationAnswer) *string {
+	var parts []string
+	if description != nil && strings.TrimSpace(*description) != "" {
+		parts = append(parts, strings.TrimSuffix(strings.TrimSpace(*description), "."))
+	}
+
+	var answered []string
+	for _, a := range answers {
+		answer := strings.TrimSpace(a.Answer)
+		if answer == "" {
+			continue
+		}
+		answered = append(answered, strings.TrimSpace(a.Question)+" "+strings.TrimSuffix(answer, "."))
+	}
+	if len(answered) > 0 {
+		parts = append(parts, "Answers to clarifying questions: "+strings.Join(answered, "; "))
+	}
+
+	if len(parts) == 0 {
+		return description
+	}
+	clarified := strings.Join(parts, ". ")
+	return &clarified
+}
+
+// applyClarification flags food results below the clarification threshold and drops the
+// clarifying questions and alternatives of confident results
+func (s *NutritionService) applyClarification(response *ScanOutput) {
+	response.NeedsClarification = response.IsFood && response.Confidence < s.clarificationThreshold
+	if !response.NeedsClarification {
+		response.ClarifyingQuestions = nil
+		response.Alternatives = nil
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dogab/vitalstack/api/pkg/types"
+)
+
+// FixtureMode selects whether scan results are recorded to or replayed from fixture files
+type FixtureMode string
+
+const (
+	// FixtureModeOff runs scans without fixtures
+	FixtureModeOff FixtureMode = "off"
+	// FixtureModeRecord runs the food scan flow and stores every result as a fixture
+	FixtureModeRecord FixtureMode = "record"
+	// FixtureModeReplay serves the recorded fixtures instead of calling the model
+	FixtureModeReplay FixtureMode = "replay"
+)
+
+// FixtureModes lists all supported fixture modes
+var FixtureModes = []FixtureMode{FixtureModeOff, FixtureModeRecord, FixtureModeReplay}
+
+// ScanFixture is a recorded scan result, stored as <image sha256>-<input hash>.json in the fixture directory
+type ScanFixture struct {
+	ImageSHA256   string      `json:"image_sha256"`
+	Description   *string     `json:"description,omitempty"`
+	PromptVersion string      `json:"prompt_version,omitempty"`
+	RecordedAt    time.Time   `json:"recorded_at"`
+	Output        *ScanOutput `json:"output"`
+}
+
+// WithFixtures records scan results to or replays them from the fixture directory dir,
+// so that tests and offline development get realistic results deterministically
+func WithFixtures(mode FixtureMode, dir string) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.fixtureMode = mode
+		s.fixtureDir = dir
+	}
+}
+
+// ParseFixtureMode validates the fixture mode, an empty mode disables fixtures
+func ParseFixtureMode(mode string) (FixtureMode, error) {
+	if mode == "" {
+		return FixtureModeOff, nil
+	}
+	for _, m := range FixtureModes {
+		if FixtureMode(mode) == m {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported fixture mode %q, expected one of %v", mode, FixtureModes)
+}
+
+// fixtureKey identifies the fixture of a scan by the same inputs as the scan cache key, so that scans of
+// an image with another description (e.g. clarified scans) or prompt version do not share fixtures.
+// The key starts with the image hash, so that the fixtures of an image are easy to find.
+func fixtureKey(image []byte, description *string, promptVersion string) string {
+	imageSum := sha256.Sum256(image)
+	h := sha256.New()
+	h.Write([]byte(promptVersion))
+	if description != nil {
+		h.Write([]byte{0})
+		h.Write([]byte(strings.TrimSpace(*description)))
+	}
+	return hex.EncodeToString(imageSum[:]) + "-" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// fixturePath returns the path of the fixture file for the key
+func (s *NutritionService) fixturePath(key string) string {
+	return filepath.Join(s.fixtureDir, key+".json")
+}
+
+// replayFixture returns the recorded scan result of the image and input. Scans without a fixture
+// are answered with a not found error, since replay mode never calls the model.
+func (s *NutritionService) replayFixture(ctx context.Context, image []byte, input *ScanInput, promptVersion string, onProgress ScanProgressFunc) (*ScanOutput, error) {
+	key := fixtureKey(image, input.Description, promptVersion)
+	data, err := os.ReadFile(s.fixturePath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			slog.WarnContext(ctx, "no scan fixture recorded for image and input", "fixture", key, "dir", s.fixtureDir)
+			return nil, types.NewNotFoundError(fmt.Sprintf("no recorded scan for this image and description (fixture %s)", key))
+		}
+		return nil, fmt.Errorf("failed to read scan fixture: %w", err)
+	}
+
+	var fixture ScanFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid scan fixture %q: %w", key, err)
+	}
+	if fixture.Output == nil {
+		return nil, fmt.Errorf("scan fixture %q has no output", key)
+	}
+
+	slog.InfoContext(ctx, "replaying recorded scan", "fixture", key, "recorded_at", fixture.RecordedAt)
+	if err := reportIngredients(ctx, onProgress, fixture.Output); err != nil {
+		return nil, err
+	}
+	return fixture.Output, nil
+}
+
+// recordFixture stores the scan result of the image and input. Failures are logged, the scan itself succeeded.
+func (s *NutritionService) recordFixture(image []byte, input *ScanInput, promptVersion string, output *ScanOutput) {
+	key := fixtureKey(image, input.Description, promptVersion)
+	imageSum := sha256.Sum256(image)
+	fixture := ScanFixture{
+		ImageSHA256:   hex.EncodeToString(imageSum[:]),
+		Description:   input.Description,
+		PromptVersion: promptVersion,
+		RecordedAt:    time.Now().UTC(),
+		Output:        output,
+	}
+
+	if err := writeFixture(s.fixturePath(key), &fixture); err != nil {
+		slog.Warn("failed to record scan fixture", "fixture", key, "error", err)
+		return
+	}
+	slog.Info("recorded scan fixture", "fixture", key, "dir", s.fixtureDir)
+}
+
+// writeFixture writes the fixture to a temporary file first, so that concurrent replays never read partial files
+func writeFixture(path string, fixture *ScanFixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fixture-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // the file is gone after a successful rename
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // the write error is returned
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/dogab/vitalstack/api/pkg/types"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// testImage is a 1x1 PNG, the fixtures in testdata/fixtures are recorded for it
+const testImage = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
+
+func TestFixtureKey(t *testing.T) {
+	image := []byte("image")
+	description := "oatmeal"
+	base := fixtureKey(image, &description, "v1")
+
+	tests := []struct {
+		name        string
+		image       []byte
+		description *string
+		version     string
+		same        bool
+	}{
+		{name: "same inputs", image: image, description: &description, version: "v1", same: true},
+		{name: "surrounding whitespace", image: image, description: ptr(" oatmeal\n"), version: "v1", same: true},
+		{name: "other image", image: []byte("other"), description: &description, version: "v1"},
+		{name: "other description", image: image, description: ptr("oatmeal with honey"), version: "v1"},
+		{name: "no description", image: image, version: "v1"},
+		{name: "empty description", image: image, description: ptr(""), version: "v1"},
+		{name: "other prompt version", image: image, description: &description, version: "v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := fixtureKey(tt.image, tt.description, tt.version)
+			if same := key == base; same != tt.same {
+				t.Errorf("fixtureKey() = %q, base key %q, want same %v", key, base, tt.same)
+			}
+			// The key starts with the image hash, so that all fixtures of an image share the prefix
+			if tt.same || string(tt.image) == string(image) {
+				if key[:64] != base[:64] {
+					t.Errorf("fixtureKey() image hash = %q, want %q", key[:64], base[:64])
+				}
+			}
+		})
+	}
+}
+
+// newReplayService creates a service replaying the fixtures of testdata/fixtures with the test prompt
+func newReplayService(t *testing.T) *NutritionService {
+	t.Helper()
+	g := genkit.Init(context.Background())
+	scanPrompts, err := LoadScanPrompts(g, os.DirFS("testdata/prompts"))
+	if err != nil {
+		t.Fatalf("LoadScanPrompts() error = %v", err)
+	}
+	return NewNutritionService(g, nil,
+		WithScanPrompts(scanPrompts...),
+		WithFixtures(FixtureModeReplay, "testdata/fixtures"),
+		WithModelUnavailable(errors.New("no AI provider in tests")),
+	)
+}
+
+func TestReplayFixture(t *testing.T) {
+	svc := newReplayService(t)
+
+	var streamed []string
+	output, err := svc.ScanFoodStream(context.Background(), &ScanInput{
+		ImageBase64: testImage,
+		Description: ptr("oatmeal with blueberries"),
+	}, func(ctx context.Context, progress *ScanProgress) error {
+		if progress.Stage == ScanStageIngredient {
+			streamed = append(streamed, progress.Ingredient.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanFoodStream() error = %v", err)
+	}
+
+	if output.FoodName != "Blueberry Oatmeal" || output.PromptVersion != "food-scan-test" {
+		t.Errorf("ScanFoodStream() = %q (prompt %q), want the recorded Blueberry Oatmeal", output.FoodName, output.PromptVersion)
+	}
+	if len(output.Ingredients) != 2 {
+		t.Fatalf("ScanFoodStream() ingredients = %d, want 2", len(output.Ingredients))
+	}
+	for _, ing := range output.Ingredients {
+		if ing.Source != IngredientSourceAIEstimate {
+			t.Errorf("ingredient %q source = %q, want %q without food database", ing.Name, ing.Source, IngredientSourceAIEstimate)
+		}
+	}
+	if got := output.TotalMacros().Calories; got != 193 {
+		t.Errorf("TotalMacros().Calories = %d, want 193", got)
+	}
+	if len(streamed) != 2 {
+		t.Errorf("streamed ingredients = %v, want the 2 recorded ingredients", streamed)
+	}
+}
+
+func TestReplayFixtureNotRecorded(t *testing.T) {
+	svc := newReplayService(t)
+
+	// Another description is another scan, replay mode never falls back to the model
+	_, err := svc.ScanFood(context.Background(), &ScanInput{
+		ImageBase64: testImage,
+		Description: ptr("oatmeal with honey"),
+	})
+	var notFound *types.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("ScanFood() error = %v, want not found error", err)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
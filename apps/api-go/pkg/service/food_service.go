@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/dogab/vitalstack/api/internal/models"
+	"github.com/dogab/vitalstack/api/internal/repository"
+	"github.com/dogab/vitalstack/api/pkg/types"
+)
+
+const (
+	// DefaultFoodSearchLimit is the number of foods returned by a search without a limit
+	DefaultFoodSearchLimit = 20
+	// MaxFoodSearchLimit caps the number of foods returned by a search
+	MaxFoodSearchLimit = 100
+)
+
+// FoodSource identifies where the reference data of a food comes from
+type FoodSource string
+
+const (
+	// FoodSourceUSDA are foods imported from USDA FoodData Central, the source ID is the FDC ID
+	FoodSourceUSDA FoodSource = "usda"
+	// FoodSourceLabel are personal foods read from a nutrition label scan
+	FoodSourceLabel FoodSource = "label"
+)
+
+// Food is a reference food of the food composition database
+type Food struct {
+	ID       int64      `json:"id,omitempty"`
+	Source   FoodSource `json:"source"`
+	SourceID string     `json:"source_id"`
+	Name     string     `json:"name"`
+	Category string     `json:"category,omitempty"`
+	// Per100g are the macros and nutrients per 100 g of the food
+	Per100g MacroData `json:"per_100g"`
+
+	// Barcode, Brand and Serving are set for packaged products
+	Barcode string   `json:"barcode,omitempty"`
+	Brand   string   `json:"brand,omitempty"`
+	Serving *Serving `json:"serving,omitempty"`
+
+	// UserID is set for personal foods, which are only visible to their user
+	UserID string `json:"user_id,omitempty"`
+}
+
+// LogValue implements slog.LogValuer for structured logging
+func (f *Food) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("id", f.ID),
+		slog.String("source", string(f.Source)),
+		slog.String("source_id", f.SourceID),
+		slog.String("name", f.Name),
+	)
+}
+
+// FoodService manages the food composition database
+type FoodService struct {
+	foodRepo      repository.FoodRepository
+	productLookup ProductLookup
+	foodLogger    FoodLogger
+}
+
+// FoodServiceOption configures the FoodService
+type FoodServiceOption func(*FoodService)
+
+// NewFoodService creates a new food service
+func NewFoodService(foodRepo repository.FoodRepository, opts ...FoodServiceOption) *FoodService {
+	s := &FoodService{foodRepo: foodRepo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SearchFoods returns the foods best matching the query. A limit of 0 uses DefaultFoodSearchLimit.
+func (s *FoodService) SearchFoods(ctx context.Context, query string, limit int) ([]Food, error) {
+	if s.foodRepo == nil {
+		return nil, errors.New("food repository is not configured")
+	}
+	if limit <= 0 {
+		limit = DefaultFoodSearchLimit
+	}
+	limit = min(limit, MaxFoodSearchLimit)
+
+	dbFoods, err := s.foodRepo.SearchFoods(ctx, query, "", limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to search foods", "query", query, "error", err)
+		return nil, fmt.Errorf("failed to search foods: %w", err)
+	}
+
+	foods := make([]Food, len(dbFoods))
+	for i := range dbFoods {
+		foods[i] = newFood(&dbFoods[i])
+	}
+	return foods, nil
+}
+
+// GetFood returns the food with the ID. Personal foods of other users are not found.
+func (s *FoodService) GetFood(ctx context.Context, userID string, id int64) (*Food, error) {
+	if s.foodRepo == nil {
+		return nil, errors.New("food repository is not configured")
+	}
+
+	dbFood, err := s.foodRepo.GetFood(ctx, id)
+	if errors.Is(err, repository.ErrFoodNotFound) || (err == nil && dbFood.UserID != nil && *dbFood.UserID != userID) {
+		return nil, types.NewNotFoundError(fmt.Sprintf("food %d not found", id))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get food: %w", err)
+	}
+
+	food := newFood(dbFood)
+	return &food, nil
+}
+
+// ListPersonalFoods returns the personal foods of the user, newest first
+func (s *FoodService) ListPersonalFoods(ctx context.Context, userID string) ([]Food, error) {
+	if s.foodRepo == nil {
+		return nil, errors.New("food repository is not configured")
+	}
+
+	dbFoods, err := s.foodRepo.ListUserFoods(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal foods: %w", err)
+	}
+
+	foods := make([]Food, len(dbFoods))
+	for i := range dbFoods {
+		foods[i] = newFood(&dbFoods[i])
+	}
+	return foods, nil
+}
+
+// ImportFoods inserts the foods into the database, foods imported before are updated
+func (s *FoodService) ImportFoods(ctx context.Context, foods []Food) error {
+	if s.foodRepo == nil {
+		return errors.New("food repository is not configured")
+	}
+
+	dbFoods := make([]models.Food, len(foods))
+	for i := range foods {
+		dbFoods[i] = newDBFood(&foods[i])
+	}
+
+	if err := s.foodRepo.UpsertFoods(ctx, dbFoods); err != nil {
+		return fmt.Errorf("failed to import foods: %w", err)
+	}
+	return nil
+}
+
+// newDBFood maps a service food to the database food
+func newDBFood(food *Food) models.Food {
+	dbFood := models.Food{
+		Source:    string(food.Source),
+		SourceID:  food.SourceID,
+		Name:      food.Name,
+		Calories:  float64(food.Per100g.Calories),
+		Protein:   food.Per100g.Protein,
+		Carbs:     food.Per100g.Carbs,
+		Fat:       food.Per100g.Fat,
+		Fiber:     food.Per100g.Fiber,
+		Nutrients: food.Per100g.Nutrients,
+	}
+	if food.Category != "" {
+		dbFood.Category = &food.Category
+	}
+	if food.Barcode != "" {
+		dbFood.Barcode = &food.Barcode
+	}
+	if food.Brand != "" {
+		dbFood.Brand = &food.Brand
+	}
+	if food.Serving != nil {
+		dbFood.ServingGrams = &food.Serving.Grams
+		dbFood.ServingUnit = &food.Serving.Unit
+		if food.Serving.Size != "" {
+			dbFood.ServingSize = &food.Serving.Size
+		}
+	}
+	if food.UserID != "" {
+		dbFood.UserID = &food.UserID
+	}
+	return dbFood
+}
+
+// newFood maps a database food to the service food
+func newFood(f *models.Food) Food {
+	food := Food{
+		ID:       f.ID,
+		Source:   FoodSource(f.Source),
+		SourceID: f.SourceID,
+		Name:     f.Name,
+		Per100g: MacroData{
+			Calories:  int(math.Round(f.Calories)),
+			Protein:   f.Protein,
+			Carbs:     f.Carbs,
+			Fat:       f.Fat,
+			Fiber:     f.Fiber,
+			Nutrients: f.Nutrients,
+		},
+	}
+	if f.Category != nil {
+		food.Category = *f.Category
+	}
+	if f.Barcode != nil {
+		food.Barcode = *f.Barcode
+	}
+	if f.Brand != nil {
+		food.Brand = *f.Brand
+	}
+	if f.UserID != nil {
+		food.UserID = *f.UserID
+	}
+	if f.ServingGrams != nil && *f.ServingGrams > 0 {
+		food.Serving = &Serving{Grams: *f.ServingGrams, Unit: "g"}
+		if f.ServingSize != nil {
+			food.Serving.Size = *f.ServingSize
+		}
+		if f.ServingUnit != nil {
+			food.Serving.Unit = *f.ServingUnit
+		}
+	}
+	return food
+}
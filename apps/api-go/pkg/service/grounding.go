@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/dogab/vitalstack/api/internal/models"
+	"github.com/dogab/vitalstack/api/internal/repository"
+)
+
+// IngredientSource tells where the macros of an ingredient come from
+type IngredientSource string
+
+const (
+	// IngredientSourceAIEstimate are macros estimated by the model
+	IngredientSourceAIEstimate IngredientSource = "ai_estimate"
+	// IngredientSourceDatabase are macros computed from the estimated weight and the reference
+	// values of a matched food of the food database
+	IngredientSourceDatabase IngredientSource = "database"
+	// IngredientSourceLabel are macros computed from the values of a scanned nutrition label
+	IngredientSourceLabel IngredientSource = "label"
+)
+
+const (
+	// DefaultGroundingMinScore is the similarity of the ingredient and food names required to
+	// replace the estimated macros with the reference values of the food
+	DefaultGroundingMinScore = 0.75
+	// groundingCandidates is the number of searched foods the best match is picked from
+	groundingCandidates = 5
+)
+
+// preparationWords describe how an ingredient is prepared rather than what it is. Reference foods
+// rarely name them the same way (e.g. "Grilled Chicken Breast" vs "Chicken, breast, roasted"),
+// so they are ignored when matching.
+var preparationWords = map[string]bool{
+	"baked": true, "boiled": true, "chopped": true, "cooked": true, "diced": true, "fresh": true,
+	"fried": true, "grilled": true, "homemade": true, "mixed": true, "pan": true, "raw": true,
+	"roasted": true, "sauteed": true, "seared": true, "shredded": true, "sliced": true,
+	"steamed": true, "toasted": true,
+}
+
+// WithFoodGrounding matches the scanned ingredients against the foods of foodRepo and recomputes
+// the macros of ingredients whose name matches a food with at least minScore
+func WithFoodGrounding(foodRepo repository.FoodRepository, minScore float64) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.foodRepo = foodRepo
+		s.grounding = true
+		s.groundingMinScore = minScore
+	}
+}
+
+// groundIngredients annotates the ingredients with the source of their macros. Ingredients with a
+// weight estimate and a confidently matched food get the macros of the food scaled to the weight.
+// Failing food lookups keep the estimates of the model, the scan itself succeeded.
+func (s *NutritionService) groundIngredients(ctx context.Context, response *ScanOutput) {
+	for i := range response.Ingredients {
+		response.Ingredients[i].Source = IngredientSourceAIEstimate
+		response.Ingredients[i].FoodID = 0
+	}
+	if !s.grounding || s.foodRepo == nil || !response.IsFood {
+		return
+	}
+
+	for i := range response.Ingredients {
+		ing := &response.Ingredients[i]
+		grams, ok := ing.Weight()
+		if !ok {
+			continue
+		}
+
+		food, score, err := s.matchFood(ctx, ing.Name)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to match ingredients against the food database", "error", err)
+			return
+		}
+		if food == nil || score < s.groundingMinScore {
+			slog.DebugContext(ctx, "no food matched ingredient", "ingredient", ing.Name, "score", score)
+			continue
+		}
+
+		slog.DebugContext(ctx, "matched ingredient", "ingredient", ing.Name, "food", food.Name, "food_id", food.ID, "score", score)
+		ing.applyFood(food, grams)
+	}
+}
+
+// matchFood returns the searched food best matching the ingredient name and its score
+func (s *NutritionService) matchFood(ctx context.Context, name string) (*models.Food, float64, error) {
+	words := nameWords(name)
+	if len(words) == 0 {
+		return nil, 0, nil
+	}
+
+	// Branded products rarely describe a generic ingredient, only reference foods are matched
+	candidates, err := s.foodRepo.SearchFoods(ctx, strings.Join(words, " "), string(FoodSourceUSDA), groundingCandidates)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var best *models.Food
+	bestScore := 0.0
+	for i := range candidates {
+		// Candidates are ranked by the search, so earlier foods win ties
+		if score := matchScore(words, candidates[i].Name); score > bestScore {
+			best, bestScore = &candidates[i], score
+		}
+	}
+	return best, bestScore, nil
+}
+
+// applyFood replaces the macros of the ingredient with the reference values of the food scaled to
+// the weight. Nutrients the food has no values for keep the estimates of the model.
+func (i *Ingredient) applyFood(food *models.Food, grams float64) {
+	i.Grams = grams
+	factor := grams / 100
+	i.Calories = int(math.Round(food.Calories * factor))
+	i.Protein = round1(food.Protein * factor)
+	i.Carbs = round1(food.Carbs * factor)
+	i.Fat = round1(food.Fat * factor)
+	i.Fiber = round1(food.Fiber * factor)
+
+	nutrients := NewNutrientValues(i.Nutrients)
+	for id, amount := range food.Nutrients {
+		if isCatalogNutrient(id) {
+			if nutrients == nil {
+				nutrients = NutrientValues{}
+			}
+			nutrients[id] = roundNutrient(id, amount*factor)
+		}
+	}
+	i.Nutrients = nutrients.Amounts()
+
+	i.Source = IngredientSourceDatabase
+	i.FoodID = food.ID
+}
+
+// groundHeadWords are food groups USDA names start with before the actual food, e.g.
+// "Fish, salmon, Atlantic" or "Seeds, chia seeds, dried"
+var groundHeadWords = map[string]bool{
+	"beverage": true, "cereal": true, "crustacean": true, "fish": true, "mollusk": true, "nut": true,
+	"seed": true, "spice": true,
+}
+
+// NameSimilarity returns the Jaccard similarity of the words of two food or ingredient names.
+// Preparation words are ignored and singular and plural forms are treated the same.
+func NameSimilarity(a, b string) float64 {
+	return jaccard(wordSet(nameWords(a)), wordSet(nameWords(b)))
+}
+
+// nameWords splits the name into lower case words without preparation words
+func nameWords(name string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, w := range fields {
+		if len(w) > 1 && !preparationWords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// matchScore is the Jaccard similarity of the ingredient words and the words describing the food.
+// Words of the food the ingredient does not name lower the score, so "milk" does not fully match
+// "Milk chocolate".
+func matchScore(words []string, foodName string) float64 {
+	return jaccard(wordSet(words), foodKeyWords(words, foodName))
+}
+
+// foodKeyWords returns the words of the food name which describe the food. Reference names list the
+// food followed by comma separated qualifiers (e.g. "Chicken, broilers or fryers, breast, meat
+// only"), so only the leading food and the qualifiers naming one of the ingredient words count.
+// A leading food group like "Fish" or "Seeds" is skipped.
+func foodKeyWords(words []string, foodName string) map[string]bool {
+	ingredient := wordSet(words)
+	key := map[string]bool{}
+	for i, segment := range strings.Split(foodName, ",") {
+		segmentWords := wordSet(nameWords(segment))
+		include := i == 0 && !isHeadSegment(segmentWords)
+		for w := range segmentWords {
+			if ingredient[w] {
+				include = true
+			}
+		}
+		if include {
+			for w := range segmentWords {
+				key[w] = true
+			}
+		}
+	}
+	return key
+}
+
+// isHeadSegment reports whether the words only name a food group
+func isHeadSegment(words map[string]bool) bool {
+	for w := range words {
+		if !groundHeadWords[w] {
+			return false
+		}
+	}
+	return len(words) > 0
+}
+
+// wordSet returns the singular forms of the words
+func wordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[singular(w)] = true
+	}
+	return set
+}
+
+// jaccard returns the number of common words divided by the number of distinct words of a and b
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// singular strips a plural s, e.g. "tomatoes" and "tomatoe" or "oats" and "oat" compare equal
+func singular(word string) string {
+	if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// round1 rounds to 1 decimal place
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		ingredient string
+		food       string
+		want       float64
+	}{
+		{"Grilled Chicken Breast", "Chicken, broilers or fryers, breast, meat only, cooked, roasted", 1},
+		{"Salmon", "Fish, salmon, Atlantic, farmed, cooked, dry heat", 1},
+		{"Chia Seeds", "Seeds, chia seeds, dried", 1},
+		{"Olive Oil", "Oil, olive, salad or cooking", 1},
+		{"Feta Cheese", "Cheese, feta", 1},
+		{"Tomatoes", "Tomatoes, red, ripe, raw, year round average", 1},
+		{"Almond Milk", "Beverages, almond milk, unsweetened, shelf stable", 1},
+		// Words of the food the ingredient does not name lower the score
+		{"Milk", "Milk chocolate", 0.5},
+		{"Chicken", "Chicken nuggets", 0.5},
+		{"Cherry Tomatoes", "Tomatoes, red, ripe, raw, year round average", 0.5},
+		{"Olive Oil Dressing", "Oil, olive, salad or cooking", 2.0 / 3},
+		{"Rice", "Snacks, rice cakes, brown rice, plain", 0.25},
+		{"Honey", "Cheese, feta", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ingredient+"/"+tt.food, func(t *testing.T) {
+			if got := matchScore(nameWords(tt.ingredient), tt.food); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("matchScore(%q, %q) = %v, want %v", tt.ingredient, tt.food, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Almonds", "almond", 1},
+		{"Grilled Chicken", "chicken", 1},
+		{"Chicken Breast", "Chicken Thigh", 1.0 / 3},
+		{"Rolled Oats", "Oat Milk", 1.0 / 3},
+		{"2% Milk", "Milk", 1},
+		{"Rice", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := NameSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("NameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := NameSimilarity(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("NameSimilarity(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dogab/vitalstack/api/pkg/cache"
+)
+
+// DefaultHealthCheckTimeout is the default maximum duration of a single health check
+const DefaultHealthCheckTimeout = 2 * time.Second
+
+// healthProbeKey is the key written and read by the cache health check
+const healthProbeKey = "health:probe"
+
+// HealthCheck checks a dependency the API needs to serve requests, e.g. the database
+type HealthCheck func(ctx context.Context) error
+
+// ComponentHealth is the result of the health check of a dependency
+type ComponentHealth struct {
+	Up      bool
+	Latency time.Duration
+	Error   string // Failure of the check, empty if the dependency is up
+}
+
+// HealthReport holds the results of all health checks
+type HealthReport struct {
+	// Ready is true if all dependencies are up
+	Ready      bool
+	Components map[string]ComponentHealth
+}
+
+// namedHealthCheck is a health check registered under the name of its dependency
+type namedHealthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// HealthService checks the dependencies of the API for the readiness probe
+type HealthService struct {
+	checks  []namedHealthCheck
+	timeout time.Duration
+}
+
+// HealthServiceOption defines a functional option for configuring the service
+type HealthServiceOption func(*HealthService)
+
+// WithHealthCheck registers the check of the named dependency
+func WithHealthCheck(name string, check HealthCheck) HealthServiceOption {
+	return func(s *HealthService) {
+		s.checks = append(s.checks, namedHealthCheck{name: name, check: check})
+	}
+}
+
+// WithHealthCheckTimeout sets the maximum duration of a single check, checks exceeding it are down.
+// A timeout of 0 only bounds the checks by the request context.
+func WithHealthCheckTimeout(timeout time.Duration) HealthServiceOption {
+	return func(s *HealthService) {
+		s.timeout = timeout
+	}
+}
+
+// NewHealthService creates a new health service
+func NewHealthService(opts ...HealthServiceOption) *HealthService {
+	svc := &HealthService{timeout: DefaultHealthCheckTimeout}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Ready runs all checks concurrently and reports whether all dependencies are up
+func (s *HealthService) Ready(ctx context.Context) *HealthReport {
+	report := &HealthReport{Ready: true, Components: make(map[string]ComponentHealth, len(s.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			health := s.runCheck(ctx, c.check)
+			if !health.Up {
+				slog.WarnContext(ctx, "health check failed", "component", c.name, "error", health.Error, "latency", health.Latency)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[c.name] = health
+			report.Ready = report.Ready && health.Up
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+// runCheck runs the check bounded by the timeout. Checks which do not observe the context, e.g.
+// calls of clients without context support, are abandoned when the timeout expires.
+func (s *HealthService) runCheck(ctx context.Context, check HealthCheck) ComponentHealth {
+	checkCtx, cancel := ctx, context.CancelFunc(func() {})
+	if s.timeout > 0 {
+		checkCtx, cancel = context.WithTimeout(ctx, s.timeout)
+	}
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- check(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-checkCtx.Done():
+		err = checkCtx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		err = fmt.Errorf("timed out after %s", s.timeout)
+	}
+
+	health := ComponentHealth{Up: err == nil, Latency: time.Since(start)}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// CacheHealthCheck checks that the cache can be written and read
+func CacheHealthCheck(c cache.Cache) HealthCheck {
+	return func(ctx context.Context) error {
+		if err := c.Set(ctx, healthProbeKey, []byte("ok"), time.Minute); err != nil {
+			return fmt.Errorf("failed to write cache: %w", err)
+		}
+		if _, found, err := c.Get(ctx, healthProbeKey); err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		} else if !found {
+			return errors.New("cache lost the probe value")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+
+	// Register decoders for the image formats whose dimensions are validated
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/dogab/vitalstack/api/pkg/types"
+)
+
+const (
+	// DefaultMaxImageBytes is the default maximum size of a decoded scan image (10MB)
+	DefaultMaxImageBytes = 10 << 20
+	// DefaultMaxImageDimension is the default maximum width or height of a scan image in pixels
+	DefaultMaxImageDimension = 8192
+)
+
+// decodeImage decodes the base64 image of the scan input and validates its size and dimensions
+// against the configured limits. If the client did not declare a mime type, it is set from the
+// detected image format.
+func (s *NutritionService) decodeImage(input *ScanInput) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(input.ImageBase64)
+	if err != nil {
+		return nil, types.NewValidationError("image is not valid base64", "image_base64", "body", "<omitted>")
+	}
+	if len(data) == 0 {
+		return nil, types.NewValidationError("image is empty", "image_base64", "body", "<omitted>")
+	}
+
+	if s.maxImageBytes > 0 && int64(len(data)) > s.maxImageBytes {
+		return nil, types.NewPayloadTooLargeError(
+			fmt.Sprintf("image is too large: %d bytes exceeds the limit of %d bytes", len(data), s.maxImageBytes))
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	switch {
+	case errors.Is(err, image.ErrFormat):
+		// Formats without a registered decoder (e.g. webp, heic) are passed to the model as is
+		return data, nil
+	case err != nil:
+		return nil, types.NewValidationError("image could not be decoded", "image_base64", "body", "<omitted>")
+	}
+
+	if s.maxImageDimension > 0 && (cfg.Width > s.maxImageDimension || cfg.Height > s.maxImageDimension) {
+		return nil, types.NewValidationError(
+			fmt.Sprintf("image dimensions %dx%d exceed the limit of %dpx", cfg.Width, cfg.Height, s.maxImageDimension),
+			"image_base64", "body", fmt.Sprintf("%dx%d", cfg.Width, cfg.Height))
+	}
+
+	if input.ImageMimeType == "" {
+		input.ImageMimeType = "image/" + format
+	}
+
+	return data, nil
+}
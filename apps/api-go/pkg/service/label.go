@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/dogab/vitalstack/api/internal/repository"
+	"github.com/dogab/vitalstack/api/pkg/types"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// ErrNotLabel is returned when the image of a label scan does not show a nutrition facts table
+var ErrNotLabel = errors.New("that doesn't look like a nutrition label. Please try again with a photo of the nutrition facts")
+
+// LabelBasis is the amount of the product a column of the nutrition facts table refers to
+type LabelBasis string
+
+const (
+	// LabelBasisPer100g are values per 100 g, or per 100 ml for beverages
+	LabelBasisPer100g LabelBasis = "per_100g"
+	// LabelBasisPerServing are values per serving of the label
+	LabelBasisPerServing LabelBasis = "per_serving"
+)
+
+// LabelColumn is a column of the nutrition facts table
+type LabelColumn struct {
+	Basis     LabelBasis       `json:"basis"`
+	Calories  float64          `json:"calories"`
+	Protein   float64          `json:"protein"`
+	Carbs     float64          `json:"carbs"`
+	Fat       float64          `json:"fat"`
+	Fiber     float64          `json:"fiber"`
+	Nutrients []NutrientAmount `json:"nutrients"`
+}
+
+// LabelOutput is the product read from a nutrition label by the nutrition label flow
+type LabelOutput struct {
+	IsLabel      bool          `json:"is_label"`
+	ProductName  string        `json:"product_name"`
+	Brand        string        `json:"brand"`
+	ServingSize  string        `json:"serving_size"`
+	ServingGrams float64       `json:"serving_grams"`
+	ServingUnit  string        `json:"serving_unit"`
+	Columns      []LabelColumn `json:"columns"`
+	Confidence   float64       `json:"confidence"`
+
+	// Model and PromptVersion identify how the result was produced. They are set by the service
+	// after the flow has run and hidden from the AI schema.
+	Model         string `json:"model,omitempty" jsonschema:"-"`
+	PromptVersion string `json:"prompt_version,omitempty" jsonschema:"-"`
+}
+
+// LabelScanOutput is the result of a label scan: the product stored in the personal foods of the
+// user and the scan result with one serving of it as the single ingredient
+type LabelScanOutput struct {
+	Scan *ScanOutput
+	Food *Food
+}
+
+// defaultMockLabel is returned by label scans while the mock scan is enabled
+var defaultMockLabel = LabelOutput{
+	IsLabel:      true,
+	ProductName:  "Chocolate Chip Granola Bar",
+	Brand:        "Mock Foods",
+	ServingSize:  "1 bar (40 g)",
+	ServingGrams: 40,
+	ServingUnit:  "g",
+	Columns: []LabelColumn{
+		{
+			Basis: LabelBasisPerServing, Calories: 180, Protein: 3, Carbs: 27, Fat: 7, Fiber: 2,
+			Nutrients: []NutrientAmount{{ID: "sugar", Amount: 11}, {ID: "saturated_fat", Amount: 2.5}, {ID: "sodium", Amount: 120}},
+		},
+		{
+			Basis: LabelBasisPer100g, Calories: 450, Protein: 7.5, Carbs: 67.5, Fat: 17.5, Fiber: 5,
+			Nutrients: []NutrientAmount{{ID: "sugar", Amount: 27.5}, {ID: "saturated_fat", Amount: 6.3}, {ID: "sodium", Amount: 300}},
+		},
+	},
+	Confidence: 0.95,
+}
+
+// WithFoodRepository sets the food database nutrition labels are stored in as personal foods
+func WithFoodRepository(foodRepo repository.FoodRepository) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.foodRepo = foodRepo
+	}
+}
+
+// WithLabelPrompt sets the nutrition label prompt loaded with LoadLabelPrompt. Without a prompt the
+// embedded default prompt is used.
+func WithLabelPrompt(labelPrompt ScanPrompt) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.labelPrompt = labelPrompt
+	}
+}
+
+// ScanLabel reads the nutrition facts of a packaged food label and stores the product in the
+// personal foods of the user. Scanning the same image again updates the product.
+func (s *NutritionService) ScanLabel(ctx context.Context, userID string, input *ScanInput) (*LabelScanOutput, error) {
+	return traced(ctx, "NutritionService.ScanLabel", func(ctx context.Context) (*LabelScanOutput, error) {
+		return s.scanLabel(ctx, userID, input)
+	})
+}
+
+// scanLabel reads the label and stores the product, see ScanLabel
+func (s *NutritionService) scanLabel(ctx context.Context, userID string, input *ScanInput) (*LabelScanOutput, error) {
+	slog.InfoContext(ctx, "received nutrition label scan request", "input", input)
+	if s.foodRepo == nil {
+		return nil, errors.New("food repository is not configured")
+	}
+
+	image, err := s.decodeImage(input)
+	if err != nil {
+		slog.WarnContext(ctx, "rejected nutrition label image", "error", err)
+		return nil, err
+	}
+
+	label, err := observeScan(s, NutritionLabelFlow, func(l *LabelOutput) bool { return l.IsLabel }, func() (*LabelOutput, error) {
+		return withScanTimeout(ctx, s.scanTimeout, func(ctx context.Context) (*LabelOutput, error) {
+			return s.runLabelScan(ctx, input)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !label.IsLabel {
+		return nil, types.NewValidationError(ErrNotLabel.Error(), "image_base64", "request.body", "<omitted>")
+	}
+
+	per100g, ok := label.per100g()
+	if !ok {
+		return nil, types.NewValidationError("the nutrition facts could not be read, please try again with a sharper photo", "image_base64", "request.body", "<omitted>")
+	}
+
+	if label.ProductName == "" {
+		label.ProductName = "Scanned Product"
+	}
+
+	// The image identifies the product, so that scanning a label twice does not duplicate it
+	sum := sha256.Sum256(image)
+	food := &Food{
+		Source:   FoodSourceLabel,
+		SourceID: userID + ":" + hex.EncodeToString(sum[:8]),
+		Name:     label.ProductName,
+		Per100g:  per100g,
+		Brand:    label.Brand,
+		UserID:   userID,
+	}
+	if label.ServingGrams > 0 {
+		food.Serving = &Serving{Size: label.ServingSize, Grams: label.ServingGrams, Unit: "g"}
+		if label.ServingUnit == "ml" {
+			food.Serving.Unit = "ml"
+		}
+	}
+
+	dbFood, err := s.foodRepo.SaveFood(ctx, newDBFood(food))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save personal food: %w", err)
+	}
+	food.ID = dbFood.ID
+
+	grams := 100.0
+	if food.Serving != nil {
+		grams = food.Serving.Grams
+	}
+	ing := food.Ingredient(grams)
+	return &LabelScanOutput{
+		Scan: &ScanOutput{
+			IsFood:         true,
+			DetectedObject: "Nutrition label",
+			FoodName:       ing.Name,
+			Confidence:     label.Confidence,
+			Ingredients:    []Ingredient{ing},
+			Model:          label.Model,
+			PromptVersion:  label.PromptVersion,
+		},
+		Food: food,
+	}, nil
+}
+
+// runLabelScan produces the label either from the mock or by running the nutrition label flow
+func (s *NutritionService) runLabelScan(ctx context.Context, input *ScanInput) (*LabelOutput, error) {
+	if s.mockScan {
+		label := defaultMockLabel
+		label.Model = mockModelName
+		slog.InfoContext(ctx, "returning mocked nutrition label", "product", label.ProductName)
+		return &label, nil
+	}
+
+	label, model, err := generateWithFallback(ctx, s, func(ctx context.Context, model string) (*LabelOutput, error) {
+		modelInput := *input
+		modelInput.Model = model
+		return s.labelFlow.Run(ctx, &modelInput)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run nutrition label flow: %w", err)
+	}
+	label.Model = model
+	label.PromptVersion = s.labelPrompt.Version
+
+	slog.DebugContext(ctx, "nutrition label response", "label", label)
+	slog.InfoContext(ctx, "nutrition label answered", "model", label.Model, "prompt_version", label.PromptVersion, "is_label", label.IsLabel)
+	return label, nil
+}
+
+// nutritionLabelFlow reads the nutrition facts table of the label image
+func (s *NutritionService) nutritionLabelFlow(ctx context.Context, input *ScanInput) (*LabelOutput, error) {
+	opts, err := s.renderPrompt(ctx, s.labelPrompt, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Model != "" {
+		opts = append(opts, ai.WithModelName(input.Model))
+	}
+
+	result, resp, err := genkit.GenerateData[LabelOutput](ctx, s.genkit, opts...)
+	s.recordUsage(NutritionLabelFlow, input.Model, resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nutrition label: %w", err)
+	}
+
+	return result, nil
+}
+
+// per100g returns the macros per 100 g, read from the per 100 g column or computed from the per
+// serving column and the serving weight. Labels without either cannot be used.
+func (l *LabelOutput) per100g() (MacroData, bool) {
+	var perServing *LabelColumn
+	for i := range l.Columns {
+		switch l.Columns[i].Basis {
+		case LabelBasisPer100g:
+			return l.Columns[i].macros(), true
+		case LabelBasisPerServing:
+			if perServing == nil {
+				perServing = &l.Columns[i]
+			}
+		}
+	}
+	if perServing == nil || l.ServingGrams <= 0 {
+		return MacroData{}, false
+	}
+	macros := perServing.macros()
+	return macros.scaled(100 / l.ServingGrams), true
+}
+
+// macros returns the values of the column, nutrients outside the catalog are dropped
+func (c *LabelColumn) macros() MacroData {
+	return MacroData{
+		Calories:  int(math.Round(c.Calories)),
+		Protein:   c.Protein,
+		Carbs:     c.Carbs,
+		Fat:       c.Fat,
+		Fiber:     c.Fiber,
+		Nutrients: NewNutrientValues(c.Nutrients),
+	}
+}
+
+// LogValue implements slog.LogValuer for structured logging
+func (l *LabelOutput) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("is_label", l.IsLabel),
+		slog.String("product_name", l.ProductName),
+		slog.String("brand", l.Brand),
+		slog.String("serving_size", l.ServingSize),
+		slog.Float64("serving_grams", l.ServingGrams),
+		slog.Int("columns", len(l.Columns)),
+		slog.Float64("confidence", l.Confidence),
+	)
+}
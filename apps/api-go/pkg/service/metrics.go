@@ -0,0 +1,110 @@
+package service
+
+import (
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes of scans recorded in the scan metrics
+const (
+	scanOutcomeFood    = "food"
+	scanOutcomeNotFood = "not_food"
+	scanOutcomeError   = "error"
+)
+
+// defaultModelLabel labels model calls without a model name, which use the Genkit default model
+const defaultModelLabel = "default"
+
+// scanMetrics records the latency and outcome of scans and the tokens used by the model
+type scanMetrics struct {
+	duration     *prometheus.HistogramVec
+	tokens       *prometheus.CounterVec
+	cacheLookups *prometheus.CounterVec
+}
+
+// WithMetrics registers the scan metrics with reg and records them for every scan
+func WithMetrics(reg prometheus.Registerer) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.metrics = newScanMetrics(reg)
+	}
+}
+
+// newScanMetrics creates the scan metrics and registers them with reg
+func newScanMetrics(reg prometheus.Registerer) *scanMetrics {
+	m := &scanMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "vitalstack",
+			Subsystem: "scan",
+			Name:      "duration_seconds",
+			Help:      "Duration of AI scans including retries and fallback models by flow and outcome (food, not_food or error)",
+			Buckets:   []float64{.25, .5, 1, 2, 4, 8, 15, 30, 60, 120},
+		}, []string{"flow", "outcome"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "vitalstack",
+			Subsystem: "scan",
+			Name:      "tokens_total",
+			Help:      "Number of tokens used by the model by flow, model and type (input, output or thoughts), if reported by the provider",
+		}, []string{"flow", "model", "type"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "vitalstack",
+			Subsystem: "scan",
+			Name:      "cache_lookups_total",
+			Help:      "Number of scan cache lookups by result (hit or miss)",
+		}, []string{"result"}),
+	}
+	reg.MustRegister(m.duration, m.tokens, m.cacheLookups)
+	return m
+}
+
+// observeScan runs the scan of the flow and records its latency and outcome. Results not showing
+// food, e.g. images without a nutrition label, are recorded as not_food.
+func observeScan[T any](s *NutritionService, flow flowName, isFood func(*T) bool, scan func() (*T, error)) (*T, error) {
+	start := time.Now()
+	result, err := scan()
+	if s.metrics == nil {
+		return result, err
+	}
+
+	outcome := scanOutcomeFood
+	switch {
+	case err != nil:
+		outcome = scanOutcomeError
+	case !isFood(result):
+		outcome = scanOutcomeNotFood
+	}
+	s.metrics.duration.WithLabelValues(string(flow), outcome).Observe(time.Since(start).Seconds())
+	return result, err
+}
+
+// recordUsage records the tokens of the model response, providers which do not report the usage are skipped
+func (s *NutritionService) recordUsage(flow flowName, model string, resp *ai.ModelResponse) {
+	if s.metrics == nil || resp == nil || resp.Usage == nil {
+		return
+	}
+	if model == "" {
+		model = defaultModelLabel
+	}
+	for tokenType, count := range map[string]int{
+		"input":    resp.Usage.InputTokens,
+		"output":   resp.Usage.OutputTokens,
+		"thoughts": resp.Usage.ThoughtsTokens,
+	} {
+		if count > 0 {
+			s.metrics.tokens.WithLabelValues(string(flow), model, tokenType).Add(float64(count))
+		}
+	}
+}
+
+// recordCacheLookup records a scan cache hit or miss
+func (s *NutritionService) recordCacheLookup(hit bool) {
+	if s.metrics == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	s.metrics.cacheLookups.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// MockSelection selects which mock response answers a scan
+type MockSelection string
+
+const (
+	// MockSelectionRoundRobin answers the scans with the responses in file order
+	MockSelectionRoundRobin MockSelection = "round-robin"
+	// MockSelectionSeed picks the responses pseudo randomly, the sequence is the same for the same seed
+	MockSelectionSeed MockSelection = "seed"
+	// MockSelectionKeyword picks the first response with a keyword contained in the scan description.
+	// Scans without a matching description fall back to round-robin.
+	MockSelectionKeyword MockSelection = "keyword"
+)
+
+// MockSelections lists all supported mock selections
+var MockSelections = []MockSelection{MockSelectionRoundRobin, MockSelectionSeed, MockSelectionKeyword}
+
+// MockScanFile is the content of a mock scan fixtures file (YAML or JSON)
+type MockScanFile struct {
+	// Latency delays every mock response without an own latency, e.g. "1.5s"
+	Latency   string         `json:"latency,omitempty"`
+	Responses []MockResponse `json:"responses"`
+}
+
+// MockResponse is a single mock scan response. It either returns the output or fails with the error.
+// Non-food results and low confidence are simulated through the output.
+type MockResponse struct {
+	Name string `json:"name"`
+	// Keywords select the response for descriptions containing one of them (case insensitive)
+	Keywords []string `json:"keywords,omitempty"`
+	// Latency delays the response, e.g. "3s". Latencies beyond the scan timeout simulate timeouts.
+	Latency string      `json:"latency,omitempty"`
+	Error   *MockError  `json:"error,omitempty"`
+	Output  *ScanOutput `json:"output,omitempty"`
+
+	latency time.Duration
+}
+
+// MockError is a simulated scan failure answered with the given HTTP status
+type MockError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// Error implements error interface
+func (e *MockError) Error() string {
+	return e.Message
+}
+
+// HTTPStatus returns the HTTP status code for the error
+func (e *MockError) HTTPStatus() int {
+	return e.Status
+}
+
+// Type returns the type of the error
+func (e *MockError) Type() string {
+	return "MOCK_SCAN_ERROR"
+}
+
+// MockScanner answers scans with configured responses instead of calling the model
+type MockScanner struct {
+	responses []MockResponse
+	selection MockSelection
+
+	mu   sync.Mutex
+	next int
+	rng  *rand.Rand
+}
+
+// NewMockScanner creates a mock scanner answering with the responses. Without responses the
+// built-in sample meals are used.
+func NewMockScanner(responses []MockResponse, selection MockSelection, seed uint64) (*MockScanner, error) {
+	if len(responses) == 0 {
+		responses = defaultMockResponses
+	}
+	if selection == "" {
+		selection = MockSelectionRoundRobin
+	}
+
+	valid := false
+	for _, s := range MockSelections {
+		valid = valid || s == selection
+	}
+	if !valid {
+		return nil, fmt.Errorf("unsupported mock selection %q, expected one of %v", selection, MockSelections)
+	}
+
+	return &MockScanner{
+		responses: responses,
+		selection: selection,
+		//nolint:gosec // mock selection does not need a cryptographically secure source
+		rng: rand.New(rand.NewPCG(seed, seed)),
+	}, nil
+}
+
+// LoadMockResponses reads the mock responses from a YAML or JSON file
+func LoadMockResponses(path string) ([]MockResponse, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // the fixtures file is provided by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock scan file: %w", err)
+	}
+
+	// YAML is converted to JSON first, so that the JSON names of the scan output apply to both formats
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mock scan file %q: %w", path, err)
+	}
+
+	var file MockScanFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid mock scan file %q: %w", path, err)
+	}
+	if len(file.Responses) == 0 {
+		return nil, fmt.Errorf("mock scan file %q contains no responses", path)
+	}
+
+	for i := range file.Responses {
+		response := &file.Responses[i]
+		if response.Name == "" {
+			response.Name = fmt.Sprintf("response-%d", i+1)
+		}
+		if (response.Output == nil) == (response.Error == nil) {
+			return nil, fmt.Errorf("mock response %q must have either an output or an error", response.Name)
+		}
+		if response.Error != nil && response.Error.Status == 0 {
+			response.Error.Status = http.StatusInternalServerError
+		}
+
+		latency := response.Latency
+		if latency == "" {
+			latency = file.Latency
+		}
+		if latency != "" {
+			if response.latency, err = time.ParseDuration(latency); err != nil {
+				return nil, fmt.Errorf("invalid latency of mock response %q: %w", response.Name, err)
+			}
+		}
+	}
+
+	return file.Responses, nil
+}
+
+// Scan returns a copy of the selected mock output, or the simulated error, after the response latency
+func (m *MockScanner) Scan(ctx context.Context, input *ScanInput) (*ScanOutput, error) {
+	response := m.selectResponse(input)
+	slog.InfoContext(ctx, "returning mocked scan response", "response", response.Name, "selection", m.selection, "latency", response.latency)
+
+	if response.latency > 0 {
+		timer := time.NewTimer(response.latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if response.Error != nil {
+		return nil, response.Error
+	}
+
+	output := *response.Output
+	output.Ingredients = append([]Ingredient(nil), response.Output.Ingredients...)
+	return &output, nil
+}
+
+// selectResponse picks the response for the scan according to the selection
+func (m *MockScanner) selectResponse(input *ScanInput) *MockResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch m.selection {
+	case MockSelectionSeed:
+		return &m.responses[m.rng.IntN(len(m.responses))]
+	case MockSelectionKeyword:
+		if input.Description != nil {
+			description := strings.ToLower(*input.Description)
+			for i := range m.responses {
+				for _, keyword := range m.responses[i].Keywords {
+					if keyword != "" && strings.Contains(description, strings.ToLower(keyword)) {
+						return &m.responses[i]
+					}
+				}
+			}
+		}
+	}
+
+	response := &m.responses[m.next%len(m.responses)]
+	m.next++
+	return response
+}
+
+// defaultMockResponses are diverse sample meals used if no mock scan file is configured
+var defaultMockResponses = []MockResponse{
+	{
+		Name:     "chicken-salad",
+		Keywords: []string{"salad", "chicken"},
+		Output: &ScanOutput{
+			IsFood:         true,
+			DetectedObject: "A salad bowl with grilled chicken",
+			FoodName:       "Grilled Chicken Salad",
+			Confidence:     0.95,
+			Ingredients: []Ingredient{
+				{Name: "Grilled Chicken Breast", Calories: 248, Protein: 38, Carbs: 0, Fat: 10, Fiber: 0, Confidence: 0.9, Grams: 150, GramsLow: 120, GramsHigh: 180, Nutrients: []NutrientAmount{{ID: "saturated_fat", Amount: 2.8}, {ID: "sodium", Amount: 110}, {ID: "cholesterol", Amount: 125}, {ID: "potassium", Amount: 390}}},
+				{Name: "Mixed Greens", Calories: 20, Protein: 2, Carbs: 3, Fat: 0, Fiber: 2, Confidence: 0.85, Grams: 60, GramsLow: 40, GramsHigh: 90},
+				{Name: "Cherry Tomatoes", Calories: 18, Protein: 1, Carbs: 4, Fat: 0, Fiber: 1, Confidence: 0.9, Grams: 60, GramsLow: 45, GramsHigh: 80, Nutrients: []NutrientAmount{{ID: "sugar", Amount: 1.6}, {ID: "potassium", Amount: 140}, {ID: "vitamin_c", Amount: 8}}},
+				{Name: "Feta Cheese", Calories: 105, Protein: 6, Carbs: 2, Fat: 8, Fiber: 0, Confidence: 0.8, Grams: 40, GramsLow: 25, GramsHigh: 55, Nutrients: []NutrientAmount{{ID: "saturated_fat", Amount: 6}, {ID: "sodium", Amount: 440}, {ID: "calcium", Amount: 200}}},
+				{Name: "Olive Oil Dressing", Calories: 80, Protein: 0, Carbs: 1, Fat: 9, Fiber: 0, Confidence: 0.5, Grams: 10, GramsLow: 5, GramsHigh: 20},
+				{Name: "Cucumber", Calories: 5, Protein: 0, Carbs: 1, Fat: 0, Fiber: 0, Confidence: 0.85, Grams: 30, GramsLow: 20, GramsHigh: 45},
+			},
+		},
+	},
+	{
+		Name:     "blueberry-oatmeal",
+		Keywords: []string{"oat", "porridge", "breakfast"},
+		Output: &ScanOutput{
+			IsFood:         true,
+			DetectedObject: "A bowl of oatmeal topped with fresh berries",
+			FoodName:       "Blueberry Oatmeal",
+			Confidence:     0.92,
+			Ingredients: []Ingredient{
+				{Name: "Rolled Oats", Calories: 150, Protein: 5, Carbs: 27, Fat: 3, Fiber: 4, Confidence: 0.85, Grams: 40, GramsLow: 30, GramsHigh: 50, Nutrients: []NutrientAmount{{ID: "sugar", Amount: 0.4}, {ID: "potassium", Amount: 145}, {ID: "iron", Amount: 1.7}}},
+				{Name: "Almond Milk", Calories: 30, Protein: 1, Carbs: 1, Fat: 2.5, Fiber: 0, Confidence: 0.6, Grams: 120, GramsLow: 80, GramsHigh: 180, Nutrients: []NutrientAmount{{ID: "sugar", Amount: 0.1}, {ID: "sodium", Amount: 85}, {ID: "calcium", Amount: 145}, {ID: "vitamin_d", Amount: 1.2}}},
+				{Name: "Blueberries", Calories: 42, Protein: 0.5, Carbs: 11, Fat: 0.2, Fiber: 1.8, Confidence: 0.9, Grams: 75, GramsLow: 55, GramsHigh: 95, Nutrients: []NutrientAmount{{ID: "sugar", Amount: 7.5}, {ID: "vitamin_c", Amount: 7.3}}},
+				{Name: "Chia Seeds", Calories: 60, Protein: 2, Carbs: 5, Fat: 4, Fiber: 4, Confidence: 0.7, Grams: 12, GramsLow: 8, GramsHigh: 18},
+				{Name: "Honey", Calories: 64, Protein: 0, Carbs: 17, Fat: 0, Fiber: 0, Confidence: 0.5, Grams: 21, GramsLow: 10, GramsHigh: 35, Nutrients: []NutrientAmount{{ID: "sugar", Amount: 17}}},
+			},
+		},
+	},
+	{
+		Name:     "salmon-rice-bowl",
+		Keywords: []string{"salmon", "rice", "fish"},
+		Output: &ScanOutput{
+			IsFood:         true,
+			DetectedObject: "A bowl of rice with pan-seared salmon",
+			FoodName:       "Salmon Rice Bowl",
+			Confidence:     0.97,
+			Ingredients: []Ingredient{
+				{Name: "Seared Salmon", Calories: 280, Protein: 25, Carbs: 0, Fat: 18, Fiber: 0, Confidence: 0.9, Grams: 120, GramsLow: 100, GramsHigh: 150, Nutrients: []NutrientAmount{{ID: "saturated_fat", Amount: 3.6}, {ID: "sodium", Amount: 70}, {ID: "cholesterol", Amount: 75}, {ID: "vitamin_d", Amount: 13}, {ID: "vitamin_b12", Amount: 3.8}}},
+				{Name: "Jasmine Rice", Calories: 205, Protein: 4, Carbs: 45, Fat: 0.4, Fiber: 0.6, Confidence: 0.85, Grams: 160, GramsLow: 120, GramsHigh: 200},
+				{Name: "Avocado", Calories: 160, Protein: 2, Carbs: 8, Fat: 15, Fiber: 6, Confidence: 0.85, Grams: 100, GramsLow: 75, GramsHigh: 125, Nutrients: []NutrientAmount{{ID: "saturated_fat", Amount: 2.1}, {ID: "potassium", Amount: 485}, {ID: "vitamin_c", Amount: 10}}},
+				{Name: "Sesame Seeds", Calories: 52, Protein: 1.6, Carbs: 2.1, Fat: 4.5, Fiber: 1.1, Confidence: 0.7, Grams: 9, GramsLow: 5, GramsHigh: 14},
+				{Name: "Soy Sauce", Calories: 9, Protein: 1.3, Carbs: 0.8, Fat: 0, Fiber: 0, Confidence: 0.5, Grams: 15, GramsLow: 8, GramsHigh: 25, Nutrients: []NutrientAmount{{ID: "sodium", Amount: 880}}},
+			},
+		},
+	},
+}
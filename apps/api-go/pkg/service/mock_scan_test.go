@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestMockScannerKeywordSelection(t *testing.T) {
+	responses := []MockResponse{
+		{Name: "salad", Keywords: []string{"salad", "Chicken"}},
+		{Name: "porridge", Keywords: []string{"oat", "porridge"}},
+		{Name: "no-keywords"},
+	}
+
+	tests := []struct {
+		name        string
+		description *string
+		want        string
+	}{
+		{name: "keyword", description: ptr("a bowl of porridge"), want: "porridge"},
+		{name: "case insensitive", description: ptr("CHICKEN wrap"), want: "salad"},
+		{name: "first response wins", description: ptr("oat salad"), want: "salad"},
+		{name: "part of a word", description: ptr("oatmeal"), want: "porridge"},
+		{name: "no matching keyword", description: ptr("pizza"), want: "salad"},
+		{name: "no description", want: "salad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMockScanner(responses, MockSelectionKeyword, 0)
+			if err != nil {
+				t.Fatalf("NewMockScanner() error = %v", err)
+			}
+			if got := m.selectResponse(&ScanInput{Description: tt.description}).Name; got != tt.want {
+				t.Errorf("selectResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockScannerKeywordFallback(t *testing.T) {
+	m, err := NewMockScanner([]MockResponse{{Name: "first"}, {Name: "second"}}, MockSelectionKeyword, 0)
+	if err != nil {
+		t.Fatalf("NewMockScanner() error = %v", err)
+	}
+
+	// Scans without a matching keyword are answered round-robin
+	for _, want := range []string{"first", "second", "first"} {
+		if got := m.selectResponse(&ScanInput{Description: ptr("pizza")}).Name; got != want {
+			t.Errorf("selectResponse() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestNewMockScannerInvalidSelection(t *testing.T) {
+	if _, err := NewMockScanner(nil, "random", 0); err == nil {
+		t.Error("NewMockScanner() error = nil, want error for unsupported selection")
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+
+	"github.com/dogab/vitalstack/api/pkg/types"
+)
+
+// Nutrient describes a nutrient tracked in addition to the macros
+type Nutrient struct {
+	// ID is the stable key of the nutrient in the AI output, the database and the API.
+	// The handlebars tag exposes it as {{id}} to the prompt templates.
+	ID   string `json:"id" handlebars:"id"`
+	Name string `json:"name"`
+	// Unit is the unit of the amounts, e.g. "g", "mg" or "µg"
+	Unit string `json:"unit"`
+}
+
+// NutrientCatalog lists the tracked nutrients in display order. Nutrients are added by
+// appending them here, the AI prompt, storage and API pick them up by their ID.
+var NutrientCatalog = []Nutrient{
+	{ID: "sugar", Name: "Sugar", Unit: "g"},
+	{ID: "saturated_fat", Name: "Saturated fat", Unit: "g"},
+	{ID: "sodium", Name: "Sodium", Unit: "mg"},
+	{ID: "cholesterol", Name: "Cholesterol", Unit: "mg"},
+	{ID: "potassium", Name: "Potassium", Unit: "mg"},
+	{ID: "calcium", Name: "Calcium", Unit: "mg"},
+	{ID: "iron", Name: "Iron", Unit: "mg"},
+	{ID: "vitamin_a", Name: "Vitamin A", Unit: "µg"},
+	{ID: "vitamin_c", Name: "Vitamin C", Unit: "mg"},
+	{ID: "vitamin_d", Name: "Vitamin D", Unit: "µg"},
+	{ID: "vitamin_b12", Name: "Vitamin B12", Unit: "µg"},
+}
+
+// isCatalogNutrient reports whether the nutrient ID is part of the catalog
+func isCatalogNutrient(id string) bool {
+	for _, n := range NutrientCatalog {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// NutrientAmount is the amount of a catalog nutrient in an ingredient, in the unit of the nutrient.
+// A list is used in the AI output instead of a map, since not all providers support free-form objects.
+type NutrientAmount struct {
+	ID     string  `json:"id"`
+	Amount float64 `json:"amount"`
+}
+
+// NutrientValues maps nutrient IDs to amounts, e.g. the nutrient totals of a meal
+type NutrientValues map[string]float64
+
+// NewNutrientValues converts the amounts into values, dropping nutrients which are not in the catalog
+func NewNutrientValues(amounts []NutrientAmount) NutrientValues {
+	if len(amounts) == 0 {
+		return nil
+	}
+	values := NutrientValues{}
+	for _, a := range amounts {
+		if isCatalogNutrient(a.ID) {
+			values[a.ID] += a.Amount
+		}
+	}
+	return values
+}
+
+// Amounts converts the values into amounts in catalog order
+func (v NutrientValues) Amounts() []NutrientAmount {
+	if len(v) == 0 {
+		return nil
+	}
+	amounts := make([]NutrientAmount, 0, len(v))
+	for id, amount := range v {
+		amounts = append(amounts, NutrientAmount{ID: id, Amount: amount})
+	}
+	sort.Slice(amounts, func(i, j int) bool { return catalogIndex(amounts[i].ID) < catalogIndex(amounts[j].ID) })
+	return amounts
+}
+
+// Add adds the other values, creating the map if necessary
+func (v NutrientValues) Add(other NutrientValues) NutrientValues {
+	if len(other) == 0 {
+		return v
+	}
+	if v == nil {
+		v = NutrientValues{}
+	}
+	for id, amount := range other {
+		v[id] += amount
+	}
+	return v
+}
+
+// validateNutrients rejects nutrients which are not in the catalog, so that typos are not stored as new nutrients
+func validateNutrients(field string, amounts []NutrientAmount) error {
+	for _, a := range amounts {
+		if !isCatalogNutrient(a.ID) {
+			return types.NewValidationError(fmt.Sprintf("unknown nutrient %q", a.ID), field, "body", a.ID)
+		}
+	}
+	return nil
+}
+
+// rounded returns the values rounded to the precision of their unit for clean output
+func (v NutrientValues) rounded() NutrientValues {
+	for id, amount := range v {
+		v[id] = roundNutrient(id, amount)
+	}
+	return v
+}
+
+// roundNutrient rounds the amount to 1 decimal place, or to 2 for nutrients measured in µg, so that
+// the small amounts of e.g. vitamin B12 in a portion do not round to 0
+func roundNutrient(id string, amount float64) float64 {
+	for _, n := range NutrientCatalog {
+		if n.ID == id && n.Unit == "µg" {
+			return math.Round(amount*100) / 100
+		}
+	}
+	return round1(amount)
+}
+
+// LogValue implements slog.LogValuer for structured logging
+func (v NutrientValues) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(v))
+	for _, a := range v.Amounts() {
+		attrs = append(attrs, slog.Float64(a.ID, a.Amount))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// catalogIndex returns the position of the nutrient in the catalog, unknown nutrients sort last
+func catalogIndex(id string) int {
+	for i, n := range NutrientCatalog {
+		if n.ID == id {
+			return i
+		}
+	}
+	return len(NutrientCatalog)
+}
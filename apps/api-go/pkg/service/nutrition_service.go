@@ -2,32 +2,70 @@ package service
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
 	"fmt"
 	"log/slog"
-	"math/big"
 	"strconv"
 	"time"
 
 	"github.com/dogab/vitalstack/api/internal/models"
 	"github.com/dogab/vitalstack/api/internal/repository"
+	"github.com/dogab/vitalstack/api/pkg/cache"
 	"github.com/dogab/vitalstack/api/pkg/types"
 
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/core"
 	"github.com/firebase/genkit/go/genkit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ErrNotFood is returned when the image does not contain food
 var ErrNotFood = errors.New("that doesn't look like food. Please try again with a different image")
 
+const (
+	// DefaultScanTimeout is the default maximum duration of a food scan
+	DefaultScanTimeout = 60 * time.Second
+	// mockModelName is recorded as the model of mocked scan results
+	mockModelName = "mock"
+)
+
 // NutritionService is a service for nutritional information
 type NutritionService struct {
 	genkit      *genkit.Genkit
-	flows       map[flowName]*core.Flow[*ScanInput, *ScanOutput, struct{}]
+	flows       map[flowName]*core.Flow[*ScanInput, *ScanOutput, *ScanProgress]
 	foodLogRepo repository.FoodLogRepository
-	mockScan    bool // If true, ScanFood returns diverse dummy data
+	mockScan    bool         // If true, ScanFood returns the responses of mockScanner
+	mockScanner *MockScanner // Mock responses, the built-in sample meals if not set
+
+	maxImageBytes     int64 // Maximum size of a decoded scan image, 0 disables the check
+	maxImageDimension int   // Maximum width or height of a scan image, 0 disables the check
+
+	generationConfig any           // Model configuration passed to every generate call, nil uses the provider defaults
+	models           []string      // Ordered list of models to try, empty uses the Genkit default model
+	retryPolicy      RetryPolicy   // Retries of model calls failing with a transient error
+	modelUnavailable error         // Why the models cannot be called, e.g. missing credentials, nil if they can
+	scanTimeout      time.Duration // Maximum duration of a scan including retries, 0 disables the timeout
+
+	scanPrompts []ScanPrompt // Variants of the food scan prompt, one is picked per scan
+
+	clarificationThreshold float64 // Confidence below which clarifying questions are returned
+
+	scanCache     cache.Cache // Optional cache of scan results keyed by image and description
+	scanCacheTTL  time.Duration
+	cacheCounters scanCacheCounters
+
+	fixtureMode FixtureMode // Records scan results to or replays them from fixtureDir
+	fixtureDir  string
+
+	foodRepo          repository.FoodRepository // Food database the scanned ingredients are matched against and labels are stored in
+	grounding         bool                      // If true, the scanned ingredients are matched against the food database
+	groundingMinScore float64                   // Minimum match score to use the reference values of a food
+
+	labelPrompt ScanPrompt                                     // Prompt of the nutrition label flow
+	labelFlow   *core.Flow[*ScanInput, *LabelOutput, struct{}] // Reads nutrition labels into products
+
+	metrics *scanMetrics // Optional Prometheus metrics of scans
 }
 
 // NutritionServiceOption defines a functional option for configuring the service
@@ -40,46 +78,159 @@ func WithMockScan(mock bool) NutritionServiceOption {
 	}
 }
 
+// WithMockScanner sets the mock responses returned when the mock scan is enabled
+func WithMockScanner(m *MockScanner) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.mockScanner = m
+	}
+}
+
+// WithImageLimits sets the maximum decoded size in bytes and the maximum width or height
+// in pixels of scanned images. A value of 0 disables the respective check.
+func WithImageLimits(maxBytes int64, maxDimension int) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.maxImageBytes = maxBytes
+		s.maxImageDimension = maxDimension
+	}
+}
+
+// WithGenerationConfig sets the model configuration (e.g. temperature) passed to every generate call
+func WithGenerationConfig(config any) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.generationConfig = config
+	}
+}
+
+// WithModels sets the ordered list of fully qualified model names (e.g. "googleai/gemini-2.5-flash").
+// The first model is the primary model, the following ones are tried in order when it fails.
+func WithModels(models ...string) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.models = models
+	}
+}
+
+// WithModelUnavailable marks the models as unavailable, e.g. because the AI provider has no credentials.
+// Scans which need the model are answered with a service unavailable error stating the reason, while
+// cached, replayed and mocked scans keep working.
+func WithModelUnavailable(reason error) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.modelUnavailable = reason
+	}
+}
+
+// WithRetryPolicy sets the retry policy for model calls failing with a transient error
+func WithRetryPolicy(policy RetryPolicy) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.retryPolicy = policy
+	}
+}
+
+// WithScanTimeout sets the maximum duration of a food scan including retries and fallback models.
+// A timeout of 0 only bounds the scan by the request context.
+func WithScanTimeout(timeout time.Duration) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.scanTimeout = timeout
+	}
+}
+
+// WithScanPrompts sets the variants of the food scan prompt loaded with LoadScanPrompts.
+// Each scan uses one of them at random. Without prompts the embedded default prompt is used.
+func WithScanPrompts(scanPrompts ...ScanPrompt) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.scanPrompts = scanPrompts
+	}
+}
+
+// WithScanCache sets the cache used to return previous results for identical scans.
+// A ttl of 0 uses the default expiry of the cache.
+func WithScanCache(c cache.Cache, ttl time.Duration) NutritionServiceOption {
+	return func(s *NutritionService) {
+		s.scanCache = c
+		s.scanCacheTTL = ttl
+	}
+}
+
 // NewNutritionService creates a new nutrition service
 func NewNutritionService(genkit *genkit.Genkit, foodLogRepo repository.FoodLogRepository, opts ...NutritionServiceOption) *NutritionService {
 	svc := &NutritionService{
 		genkit:      genkit,
-		flows:       map[flowName]*core.Flow[*ScanInput, *ScanOutput, struct{}]{},
+		flows:       map[flowName]*core.Flow[*ScanInput, *ScanOutput, *ScanProgress]{},
 		foodLogRepo: foodLogRepo,
+
+		maxImageBytes:     DefaultMaxImageBytes,
+		maxImageDimension: DefaultMaxImageDimension,
+		retryPolicy:       DefaultRetryPolicy,
+		scanTimeout:       DefaultScanTimeout,
+		fixtureMode:       FixtureModeOff,
+
+		clarificationThreshold: DefaultClarificationThreshold,
+		groundingMinScore:      DefaultGroundingMinScore,
 	}
 
 	for _, opt := range opts {
 		opt(svc)
 	}
 
+	if svc.mockScan && svc.mockScanner == nil {
+		// The built-in responses and selection are always valid
+		svc.mockScanner, _ = NewMockScanner(nil, MockSelectionRoundRobin, 0)
+	}
+
+	if len(svc.scanPrompts) == 0 {
+		svc.scanPrompts = mustLoadDefaultScanPrompts(genkit)
+	}
+	if svc.labelPrompt.prompt == nil {
+		svc.labelPrompt = mustLoadDefaultLabelPrompt(genkit)
+	}
+
 	svc.initializeFlows()
 	return svc
 }
 
 // initializeFlows initializes all AI flows for genkit and stores them in the flows map
 func (s *NutritionService) initializeFlows() {
-	s.flows = map[flowName]*core.Flow[*ScanInput, *ScanOutput, struct{}]{
-		FoodScanFlow: genkit.DefineFlow(s.genkit, string(FoodScanFlow), s.foodScanFlow),
+	s.flows = map[flowName]*core.Flow[*ScanInput, *ScanOutput, *ScanProgress]{
+		FoodScanFlow: genkit.DefineStreamingFlow(s.genkit, string(FoodScanFlow), s.foodScanFlow),
 	}
+	s.labelFlow = genkit.DefineFlow(s.genkit, string(NutritionLabelFlow), s.nutritionLabelFlow)
+}
+
+// ModelUnavailable returns why the models cannot be called, or nil if they can (see WithModelUnavailable)
+func (s *NutritionService) ModelUnavailable() error {
+	return s.modelUnavailable
 }
 
 // ScanFood scans the food in the image and returns the nutritional information
 func (s *NutritionService) ScanFood(ctx context.Context, input *ScanInput) (*ScanOutput, error) {
-	slog.Info("received food scan request", "input", input)
+	return traced(ctx, "NutritionService.ScanFood", func(ctx context.Context) (*ScanOutput, error) {
+		slog.InfoContext(ctx, "received food scan request", "input", input)
+		return rejectNonFood(s.scanFood(ctx, input, nil))
+	})
+}
 
-	if s.mockScan {
-		slog.Info("returning dynamically mocked scan data (bypassing Genkit)")
-		return s.generateMockScan(), nil
-	}
+// ScanFoodStream scans the food like ScanFood and reports the progress to onProgress while
+// the model response is streamed
+func (s *NutritionService) ScanFoodStream(ctx context.Context, input *ScanInput, onProgress ScanProgressFunc) (*ScanOutput, error) {
+	return traced(ctx, "NutritionService.ScanFoodStream", func(ctx context.Context) (*ScanOutput, error) {
+		slog.InfoContext(ctx, "received streaming food scan request", "input", input)
+		return rejectNonFood(s.scanFood(ctx, input, onProgress))
+	})
+}
 
-	flow := s.flows[FoodScanFlow]
-	response, err := flow.Run(ctx, input)
+// AnalyzeFood scans the image like ScanFood but returns the result of images without food
+// instead of rejecting them, e.g. to evaluate the non-food detection
+func (s *NutritionService) AnalyzeFood(ctx context.Context, input *ScanInput) (*ScanOutput, error) {
+	return traced(ctx, "NutritionService.AnalyzeFood", func(ctx context.Context) (*ScanOutput, error) {
+		return s.scanFood(ctx, input, nil)
+	})
+}
+
+// rejectNonFood returns a validation error for scan results of images without food
+func rejectNonFood(response *ScanOutput, err error) (*ScanOutput, error) {
 	if err != nil {
-		return nil, fmt.Errorf("failed to run food scan flow: %w", err)
+		return nil, err
 	}
 
-	slog.Debug("food scan response", "response", response)
-
 	// Check if the image contains food
 	if !response.IsFood {
 		return nil, types.NewValidationError(ErrNotFood.Error(), "image_base64", "request.body", "<omitted>")
@@ -88,72 +239,145 @@ func (s *NutritionService) ScanFood(ctx context.Context, input *ScanInput) (*Sca
 	return response, nil
 }
 
-func (s *NutritionService) foodScanFlow(ctx context.Context, input *ScanInput) (*ScanOutput, error) {
-	systemPrompt := `You are an expert nutritionist and food recognition AI.
-Analyze the provided image and determine if it contains food.
-
-FIRST: Determine if the image contains food
-- Set is_food to true if the image contains any food items
-- Set is_food to false if the image does NOT contain food (e.g., objects, people, landscapes, text, documents)
-- Set detected_object to describe what you see (e.g., "Grilled Chicken Salad" or "Laptop computer")
-
-IF THE IMAGE CONTAINS FOOD (is_food = true):
-Identify each visible ingredient and estimate its individual macros.
-
-ANALYSIS STEPS:
-1. Identify all visible food items and ingredients
-2. For each ingredient, estimate its weight in grams
-3. For each ingredient, calculate its individual macros (calories, protein, carbs, fat, fiber)
-4. Consider cooking methods (fried, grilled, steamed) — reflect them in the ingredient macros
-5. Estimate portion sizes relative to standard references (e.g., a fist ≈ 1 cup, palm ≈ 3oz protein)
-
-OUTPUT REQUIREMENTS:
-- is_food: true if image contains food, false otherwise
-- detected_object: What you see in the image
-- food_name: Overall meal/dish name (empty string if not food)
-- confidence: How clearly the food is identifiable (0.0-1.0, or 0.0 if not food)
-- ingredients: Array of each component with:
-  - name: Ingredient name (e.g., "Grilled Chicken Breast")
-  - serving_size: Integer representing the raw unit size or standardized amount (e.g. 100)
-  - serving_quantity: Float representing how many servings are present (e.g., 1.5)
-  - serving_unit: String representing the unit (e.g., "g", "slice", "cup", "oz")
-  - calories: Calories for this ingredient at the estimated weight
-  - protein: Protein in grams
-  - carbs: Carbohydrates in grams
-  - fat: Fat in grams
-  - fiber: Fiber in grams
-
-IMPORTANT: Do NOT return total macros. Only return per-ingredient data.
-Total macros will be computed by summing all ingredients.
-
-IF THE IMAGE DOES NOT CONTAIN FOOD (is_food = false):
-Return minimal response with is_food=false and detected_object describing what you see.
-
-GUIDELINES:
-- Always break down complex meals into their visible components
-- Use reasonable middle-ground estimates when portions are unclear
-- Include cooking oils, sauces, and dressings as separate ingredients when visible
-- Include fiber in macro calculations when applicable`
-
-	// Build the user prompt text
-	userPrompt := "Analyze this image. First determine if it contains food, then identify each ingredient with its macros."
-	if input.Description != nil && *input.Description != "" {
-		userPrompt = fmt.Sprintf("Analyze this image. Additional context: %s. First determine if it contains food, then identify each ingredient with its macros.", *input.Description)
-	}
-
-	// Build the image data URL for multimodal input
-	imageDataURL := "data:image/jpeg;base64," + input.ImageBase64
-
-	// Generate structured output using proper multimodal input
-	result, _, err := genkit.GenerateData[ScanOutput](ctx, s.genkit,
-		ai.WithSystem(systemPrompt),
-		ai.WithMessages(
-			ai.NewUserMessage(
-				ai.NewMediaPart("image/jpeg", imageDataURL),
-				ai.NewTextPart(userPrompt),
-			),
-		),
+// scanFood validates the image and returns the cached or freshly generated scan result.
+// If onProgress is set, the model response is streamed.
+func (s *NutritionService) scanFood(ctx context.Context, input *ScanInput, onProgress ScanProgressFunc) (*ScanOutput, error) {
+	image, err := s.decodeImage(input)
+	if err != nil {
+		slog.WarnContext(ctx, "rejected food scan image", "error", err)
+		return nil, err
+	}
+
+	// The prompt is part of the cache and fixture keys, so that prompt revisions and variants do not share results
+	prompt := s.selectScanPrompt()
+	input.Prompt = prompt.Name
+
+	if s.fixtureMode == FixtureModeReplay {
+		response, err := s.replayFixture(ctx, image, input, prompt.Version, onProgress)
+		if err != nil {
+			return nil, err
+		}
+		s.groundIngredients(ctx, response)
+		s.applyClarification(response)
+		return response, nil
+	}
+
+	cacheKey := scanCacheKey(image, input.Description, prompt.Version)
+	response, cached := s.getCachedScan(ctx, cacheKey)
+	if cached {
+		if err := reportIngredients(ctx, onProgress, response); err != nil {
+			return nil, err
+		}
+	} else {
+		response, err = s.runScanWithTimeout(ctx, input, onProgress)
+		if err != nil {
+			return nil, err
+		}
+		s.setCachedScan(ctx, cacheKey, response)
+		if s.fixtureMode == FixtureModeRecord && !s.mockScan {
+			s.recordFixture(image, input, prompt.Version, response)
+		}
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("scan.model", response.Model),
+		attribute.String("scan.prompt_version", response.PromptVersion),
+		attribute.Bool("scan.cached", cached),
+		attribute.Bool("scan.is_food", response.IsFood),
 	)
+
+	// Grounding runs after caching and recording, so that the stored results are the model output
+	// and always matched against the current food database
+	s.groundIngredients(ctx, response)
+	s.applyClarification(response)
+	return response, nil
+}
+
+// runScanWithTimeout runs the scan bounded by the scan timeout. Timeouts are returned as
+// gateway timeout errors, while cancellation by the client is passed on as context.Canceled.
+func (s *NutritionService) runScanWithTimeout(ctx context.Context, input *ScanInput, onProgress ScanProgressFunc) (*ScanOutput, error) {
+	return observeScan(s, FoodScanFlow, func(o *ScanOutput) bool { return o.IsFood }, func() (*ScanOutput, error) {
+		return withScanTimeout(ctx, s.scanTimeout, func(ctx context.Context) (*ScanOutput, error) {
+			return s.runScan(ctx, input, onProgress)
+		})
+	})
+}
+
+// withScanTimeout runs the scan bounded by the timeout, see runScanWithTimeout
+func withScanTimeout[T any](ctx context.Context, timeout time.Duration, run func(ctx context.Context) (*T, error)) (*T, error) {
+	scanCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		scanCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	response, err := run(scanCtx)
+	switch {
+	case err == nil:
+		return response, nil
+	case errors.Is(ctx.Err(), context.Canceled):
+		slog.InfoContext(ctx, "food scan canceled by client", "error", err)
+		return nil, fmt.Errorf("food scan canceled: %w", ctx.Err())
+	case errors.Is(scanCtx.Err(), context.DeadlineExceeded):
+		slog.WarnContext(ctx, "food scan timed out", "timeout", timeout, "error", err)
+		return nil, types.NewGatewayTimeoutError("the food scan took too long, please try again")
+	default:
+		return nil, err
+	}
+}
+
+// runScan produces a scan result either from the mock or by running the food scan flow
+func (s *NutritionService) runScan(ctx context.Context, input *ScanInput, onProgress ScanProgressFunc) (*ScanOutput, error) {
+	if s.mockScan {
+		response, err := s.mockScanner.Scan(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		response.Model = mockModelName
+		if err := reportIngredients(ctx, onProgress, response); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	flow := s.flows[FoodScanFlow]
+	response, model, err := generateWithFallback(ctx, s, func(ctx context.Context, model string) (*ScanOutput, error) {
+		modelInput := *input
+		modelInput.Model = model
+		if onProgress == nil {
+			return flow.Run(ctx, &modelInput)
+		}
+		return streamFlow(ctx, flow, &modelInput, onProgress)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run food scan flow: %w", err)
+	}
+	response.Model = model
+	response.PromptVersion = s.scanPrompt(input.Prompt).Version
+
+	slog.DebugContext(ctx, "food scan response", "response", response)
+	slog.InfoContext(ctx, "food scan answered", "model", response.Model, "prompt_version", response.PromptVersion, "is_food", response.IsFood)
+	return response, nil
+}
+
+// foodScanFlow analyzes the food image. If sendChunk is set, the model response is streamed
+// and every completed ingredient is sent as progress.
+func (s *NutritionService) foodScanFlow(ctx context.Context, input *ScanInput, sendChunk core.StreamCallback[*ScanProgress]) (*ScanOutput, error) {
+	opts, err := s.renderScanPrompt(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Model != "" {
+		opts = append(opts, ai.WithModelName(input.Model))
+	}
+
+	if sendChunk != nil {
+		return s.streamScan(ctx, input, sendChunk, opts...)
+	}
+
+	result, resp, err := genkit.GenerateData[ScanOutput](ctx, s.genkit, opts...)
+	s.recordUsage(FoodScanFlow, input.Model, resp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze food image: %w", err)
 	}
@@ -163,10 +387,26 @@ GUIDELINES:
 
 // LogFood handles saving an accepted scan to the database
 func (s *NutritionService) LogFood(ctx context.Context, input *LogFoodInput) (*LogFoodOutput, error) {
+	return traced(ctx, "NutritionService.LogFood", func(ctx context.Context) (*LogFoodOutput, error) {
+		return s.logFood(ctx, input)
+	})
+}
+
+// logFood saves the scan and its ingredients as a food log of the user
+func (s *NutritionService) logFood(ctx context.Context, input *LogFoodInput) (*LogFoodOutput, error) {
 	if s.foodLogRepo == nil {
 		return nil, errors.New("database repository is not configured")
 	}
 
+	if err := validateNutrients("macros.nutrients", input.Macros.Nutrients.Amounts()); err != nil {
+		return nil, err
+	}
+	for i, ing := range input.Ingredients {
+		if err := validateNutrients(fmt.Sprintf("ingredients[%d].macros.nutrients", i), ing.Nutrients); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the FoodLog database model
 	dbLog := &models.FoodLog{
 		FoodName:            input.FoodName,
@@ -177,12 +417,22 @@ func (s *NutritionService) LogFood(ctx context.Context, input *LogFoodInput) (*L
 		Carbs:               input.Macros.Carbs,
 		Fat:                 input.Macros.Fat,
 		Fiber:               input.Macros.Fiber,
+		Nutrients:           input.Macros.Nutrients,
 		CreatedAt:           time.Now().UTC(),
 	}
+	// The version is echoed by the client, so only versions of the loaded prompts are stored. Versions of
+	// prompts revised since the scan or made up by the client would falsify the accuracy per prompt revision.
+	if input.PromptVersion != "" {
+		if s.isLoadedPromptVersion(input.PromptVersion) {
+			dbLog.PromptVersion = &input.PromptVersion
+		} else {
+			slog.WarnContext(ctx, "ignoring unknown prompt version of logged scan", "prompt_version", input.PromptVersion)
+		}
+	}
 
 	err := s.foodLogRepo.CreateFoodLog(ctx, dbLog)
 	if err != nil {
-		slog.Error("Failed to save food log to Supabase", "error", err)
+		slog.ErrorContext(ctx, "Failed to save food log to Supabase", "error", err)
 		return nil, fmt.Errorf("failed to save food log: %w", err)
 	}
 
@@ -200,11 +450,19 @@ func (s *NutritionService) LogFood(ctx context.Context, input *LogFoodInput) (*L
 				Carbs:           ing.Carbs,
 				Fat:             ing.Fat,
 				Fiber:           ing.Fiber,
+				Nutrients:       NewNutrientValues(ing.Nutrients),
 				CreatedAt:       time.Now().UTC(),
 			}
+			if ing.Source != "" {
+				source := string(ing.Source)
+				dbIng.Source = &source
+			}
+			if ing.FoodID != 0 {
+				dbIng.FoodID = &ing.FoodID
+			}
 			err = s.foodLogRepo.CreateFoodLogIngredient(ctx, dbIng)
 			if err != nil {
-				slog.Error("Failed to save food ingredient to Supabase", "error", err)
+				slog.ErrorContext(ctx, "Failed to save food ingredient to Supabase", "error", err)
 			}
 		}
 	}
@@ -217,6 +475,13 @@ func (s *NutritionService) LogFood(ctx context.Context, input *LogFoodInput) (*L
 
 // GetDailyIntake retrieves aggregated daily macro data and a list of meals
 func (s *NutritionService) GetDailyIntake(ctx context.Context, userID string, tzOffsetMins int) (*DailyIntakeOutput, error) {
+	return traced(ctx, "NutritionService.GetDailyIntake", func(ctx context.Context) (*DailyIntakeOutput, error) {
+		return s.getDailyIntake(ctx, userID, tzOffsetMins)
+	})
+}
+
+// getDailyIntake aggregates the food logs of the local day of the user
+func (s *NutritionService) getDailyIntake(ctx context.Context, userID string, tzOffsetMins int) (*DailyIntakeOutput, error) {
 	if s.foodLogRepo == nil {
 		return nil, errors.New("database repository is not configured")
 	}
@@ -240,7 +505,7 @@ func (s *NutritionService) GetDailyIntake(ctx context.Context, userID string, tz
 
 	logs, err := s.foodLogRepo.GetDailyFoodLogs(ctx, userID, utcStartOfDay, utcEndOfDay)
 	if err != nil {
-		slog.Error("Failed to get daily food logs from Supabase", "error", err)
+		slog.ErrorContext(ctx, "Failed to get daily food logs from Supabase", "error", err)
 		return nil, fmt.Errorf("failed to get daily food logs: %w", err)
 	}
 
@@ -248,16 +513,20 @@ func (s *NutritionService) GetDailyIntake(ctx context.Context, userID string, tz
 	var meals []Meal
 
 	for _, log := range logs {
-		totalMacros.Calories += log.Calories
-		totalMacros.Protein += log.Protein
-		totalMacros.Carbs += log.Carbs
-		totalMacros.Fat += log.Fat
-		totalMacros.Fiber += log.Fiber
+		macros := MacroData{
+			Calories:  log.Calories,
+			Protein:   log.Protein,
+			Carbs:     log.Carbs,
+			Fat:       log.Fat,
+			Fiber:     log.Fiber,
+			Nutrients: log.Nutrients,
+		}
+		totalMacros.Add(macros)
 
 		// Map deeply nested DB ingredients back to business logic ingredients
 		var mappedIngredients []Ingredient
 		for _, ing := range log.Ingredients {
-			mappedIngredients = append(mappedIngredients, Ingredient{
+			mapped := Ingredient{
 				Name:            ing.Name,
 				ServingSize:     ing.ServingSize,
 				ServingQuantity: ing.ServingQuantity,
@@ -267,24 +536,26 @@ func (s *NutritionService) GetDailyIntake(ctx context.Context, userID string, tz
 				Carbs:           ing.Carbs,
 				Fat:             ing.Fat,
 				Fiber:           ing.Fiber,
-			})
+				Nutrients:       NutrientValues(ing.Nutrients).Amounts(),
+			}
+			if ing.Source != nil {
+				mapped.Source = IngredientSource(*ing.Source)
+			}
+			if ing.FoodID != nil {
+				mapped.FoodID = *ing.FoodID
+			}
+			mappedIngredients = append(mappedIngredients, mapped)
 		}
 
 		// Calculate local time for the meal display
 		mealLocalTime := log.CreatedAt.UTC().Add(time.Duration(-tzOffsetMins) * time.Minute)
 
 		meals = append(meals, Meal{
-			ID:       strconv.FormatInt(log.ID, 10),
-			Name:     log.FoodName,
-			Time:     mealLocalTime.Format("03:04 PM"),
-			Calories: log.Calories,
-			Macros: MacroData{
-				Calories: log.Calories,
-				Protein:  log.Protein,
-				Carbs:    log.Carbs,
-				Fat:      log.Fat,
-				Fiber:    log.Fiber,
-			},
+			ID:          strconv.FormatInt(log.ID, 10),
+			Name:        log.FoodName,
+			Time:        mealLocalTime.Format("03:04 PM"),
+			Calories:    log.Calories,
+			Macros:      macros,
 			Ingredients: mappedIngredients,
 			Emoji:       "🍽️",
 			Tag:         "",
@@ -296,6 +567,8 @@ func (s *NutritionService) GetDailyIntake(ctx context.Context, userID string, tz
 		meals[i], meals[j] = meals[j], meals[i]
 	}
 
+	totalMacros.Nutrients = totalMacros.Nutrients.rounded()
+
 	return &DailyIntakeOutput{
 		Macros: totalMacros,
 		Meals:  meals,
@@ -304,66 +577,23 @@ func (s *NutritionService) GetDailyIntake(ctx context.Context, userID string, tz
 
 // DeleteLoggedFood deletes a logged food entry by ID
 func (s *NutritionService) DeleteLoggedFood(ctx context.Context, userID string, logID int64) error {
+	return tracedErr(ctx, "NutritionService.DeleteLoggedFood", func(ctx context.Context) error {
+		return s.deleteLoggedFood(ctx, userID, logID)
+	})
+}
+
+// deleteLoggedFood deletes the food log of the user
+func (s *NutritionService) deleteLoggedFood(ctx context.Context, userID string, logID int64) error {
 	if s.foodLogRepo == nil {
 		return errors.New("database repository is not configured")
 	}
 
 	err := s.foodLogRepo.DeleteFoodLog(ctx, userID, logID)
 	if err != nil {
-		slog.Error("Failed to delete food log from Supabase", "error", err, "logID", logID)
+		slog.ErrorContext(ctx, "Failed to delete food log from Supabase", "error", err, "logID", logID)
 		return fmt.Errorf("failed to delete food log: %w", err)
 	}
 
-	slog.Info("Successfully deleted food log", "logID", logID, "userID", userID)
+	slog.InfoContext(ctx, "Successfully deleted food log", "logID", logID, "userID", userID)
 	return nil
 }
-
-// generateMockScan returns a randomized mock scan output from 3 predefined meals
-func (s *NutritionService) generateMockScan() *ScanOutput {
-	// 3 hardcoded diverse mock meals
-	meals := []ScanOutput{
-		{
-			IsFood:         true,
-			DetectedObject: "A salad bowl with grilled chicken",
-			FoodName:       "Grilled Chicken Salad",
-			Confidence:     0.95,
-			Ingredients: []Ingredient{
-				{Name: "Grilled Chicken Breast", Calories: 248, Protein: 38, Carbs: 0, Fat: 10, Fiber: 0},
-				{Name: "Mixed Greens", Calories: 20, Protein: 2, Carbs: 3, Fat: 0, Fiber: 2},
-				{Name: "Cherry Tomatoes", Calories: 18, Protein: 1, Carbs: 4, Fat: 0, Fiber: 1},
-				{Name: "Feta Cheese", Calories: 105, Protein: 6, Carbs: 2, Fat: 8, Fiber: 0},
-				{Name: "Olive Oil Dressing", Calories: 80, Protein: 0, Carbs: 1, Fat: 9, Fiber: 0},
-				{Name: "Cucumber", Calories: 5, Protein: 0, Carbs: 1, Fat: 0, Fiber: 0},
-			},
-		},
-		{
-			IsFood:         true,
-			DetectedObject: "A bowl of oatmeal topped with fresh berries",
-			FoodName:       "Blueberry Oatmeal",
-			Confidence:     0.92,
-			Ingredients: []Ingredient{
-				{Name: "Rolled Oats", Calories: 150, Protein: 5, Carbs: 27, Fat: 3, Fiber: 4},
-				{Name: "Almond Milk", Calories: 30, Protein: 1, Carbs: 1, Fat: 2.5, Fiber: 0},
-				{Name: "Blueberries", Calories: 42, Protein: 0.5, Carbs: 11, Fat: 0.2, Fiber: 1.8},
-				{Name: "Chia Seeds", Calories: 60, Protein: 2, Carbs: 5, Fat: 4, Fiber: 4},
-				{Name: "Honey", Calories: 64, Protein: 0, Carbs: 17, Fat: 0, Fiber: 0},
-			},
-		},
-		{
-			IsFood:         true,
-			DetectedObject: "A bowl of rice with pan-seared salmon",
-			FoodName:       "Salmon Rice Bowl",
-			Confidence:     0.97,
-			Ingredients: []Ingredient{
-				{Name: "Seared Salmon", Calories: 280, Protein: 25, Carbs: 0, Fat: 18, Fiber: 0},
-				{Name: "Jasmine Rice", Calories: 205, Protein: 4, Carbs: 45, Fat: 0.4, Fiber: 0.6},
-				{Name: "Avocado", Calories: 160, Protein: 2, Carbs: 8, Fat: 15, Fiber: 6},
-				{Name: "Sesame Seeds", Calories: 52, Protein: 1.6, Carbs: 2.1, Fat: 4.5, Fiber: 1.1},
-				{Name: "Soy Sauce", Calories: 9, Protein: 1.3, Carbs: 0.8, Fat: 0, Fiber: 0},
-			},
-		},
-	}
-
-	n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(meals))))
-	return &meals[n.Int64()]
-}
@@ -1,29 +1,56 @@
 package service
 
 import (
+	"context"
 	"log/slog"
 	"math"
+
+	"github.com/dogab/vitalstack/api/pkg/units"
 )
 
 type flowName string
 
 const (
-	FoodScanFlow flowName = "foodScanFlow"
+	FoodScanFlow       flowName = "foodScanFlow"
+	NutritionLabelFlow flowName = "nutritionLabelFlow"
 )
 
+// DefaultImageMimeType is assumed when the client does not declare the image type
+const DefaultImageMimeType = "image/jpeg"
+
 type ScanInput struct {
-	ImageBase64 string  `json:"image_base64"`
-	Description *string `json:"description,omitempty"`
+	ImageBase64   string  `json:"image_base64"`
+	ImageMimeType string  `json:"image_mime_type,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	// Model overrides the default model of the flow, set by the service when falling back to other models
+	Model string `json:"model,omitempty"`
+	// Prompt is the name of the prompt variant, set by the service
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// MimeType returns the declared image mime type or the default if none was set
+func (s *ScanInput) MimeType() string {
+	if s.ImageMimeType == "" {
+		return DefaultImageMimeType
+	}
+	return s.ImageMimeType
 }
 
 // LogValue implements slog.LogValuer for structured logging
 func (s *ScanInput) LogValue() slog.Value {
 	attrs := []slog.Attr{
 		slog.Int("image_size_bytes", len(s.ImageBase64)),
+		slog.String("image_mime_type", s.MimeType()),
 	}
 	if s.Description != nil {
 		attrs = append(attrs, slog.String("description", *s.Description))
 	}
+	if s.Model != "" {
+		attrs = append(attrs, slog.String("model", s.Model))
+	}
+	if s.Prompt != "" {
+		attrs = append(attrs, slog.String("prompt", s.Prompt))
+	}
 	return slog.GroupValue(attrs...)
 }
 
@@ -39,6 +66,46 @@ type Ingredient struct {
 	Carbs           float64  `json:"carbs"`
 	Fat             float64  `json:"fat"`
 	Fiber           float64  `json:"fiber"`
+	// Confidence is how certain the model is about the ingredient and its portion (0-1)
+	Confidence float64 `json:"confidence"`
+	// Grams is the estimated weight, GramsLow and GramsHigh bound the plausible weight
+	Grams     float64 `json:"grams"`
+	GramsLow  float64 `json:"grams_low"`
+	GramsHigh float64 `json:"grams_high"`
+	// Nutrients are the amounts of the catalog nutrients beyond the macros, see NutrientCatalog
+	Nutrients []NutrientAmount `json:"nutrients"`
+
+	// Source and FoodID tell whether the macros are estimated or computed from a matched food of the
+	// food database. They are set by the service and hidden from the AI schema.
+	Source IngredientSource `json:"source,omitempty" jsonschema:"-"`
+	FoodID int64            `json:"food_id,omitempty" jsonschema:"-"`
+}
+
+// Weight returns the weight of the ingredient in grams: the weight estimate, or the serving converted
+// to grams if there is none. Volumes are converted with the density of the ingredient.
+func (i *Ingredient) Weight() (float64, bool) {
+	if i.Grams > 0 {
+		return i.Grams, true
+	}
+	if i.ServingSize == nil || i.ServingUnit == nil {
+		return 0, false
+	}
+	quantity := 1.0
+	if i.ServingQuantity != nil {
+		quantity = *i.ServingQuantity
+	}
+	grams, ok := units.ToGrams(float64(*i.ServingSize)*quantity, *i.ServingUnit, i.Name)
+	return grams, ok && grams > 0
+}
+
+// CalorieRange scales the calories to the plausible weight range of the ingredient.
+// Without a weight estimate the range collapses to the calories.
+func (i *Ingredient) CalorieRange() (low, high float64) {
+	calories := float64(i.Calories)
+	if i.Grams <= 0 || i.GramsLow <= 0 || i.GramsHigh < i.GramsLow {
+		return calories, calories
+	}
+	return calories * i.GramsLow / i.Grams, calories * i.GramsHigh / i.Grams
 }
 
 // LogValue implements slog.LogValuer for structured logging
@@ -50,6 +117,17 @@ func (i *Ingredient) LogValue() slog.Value {
 		slog.Float64("carbs", i.Carbs),
 		slog.Float64("fat", i.Fat),
 		slog.Float64("fiber", i.Fiber),
+		slog.Float64("confidence", i.Confidence),
+		slog.Float64("grams", i.Grams),
+		slog.Float64("grams_low", i.GramsLow),
+		slog.Float64("grams_high", i.GramsHigh),
+		slog.Any("nutrients", NewNutrientValues(i.Nutrients)),
+	}
+	if i.Source != "" {
+		attrs = append(attrs, slog.String("source", string(i.Source)))
+	}
+	if i.FoodID != 0 {
+		attrs = append(attrs, slog.Int64("food_id", i.FoodID))
 	}
 	if i.ServingSize != nil {
 		attrs = append(attrs, slog.Int("serving_size", *i.ServingSize))
@@ -71,6 +149,17 @@ type ScanOutput struct {
 	FoodName       string       `json:"food_name"`
 	Confidence     float64      `json:"confidence"`
 	Ingredients    []Ingredient `json:"ingredients"`
+	// ClarifyingQuestions and Alternatives are only kept if the confidence is below the clarification threshold
+	ClarifyingQuestions []string `json:"clarifying_questions,omitempty"`
+	Alternatives        []string `json:"alternatives,omitempty"`
+
+	// NeedsClarification is set by the service if the confidence is below the clarification threshold
+	NeedsClarification bool `json:"needs_clarification,omitempty" jsonschema:"-"`
+
+	// Model and PromptVersion identify how the result was produced. They are set by the service
+	// after the flow has run and hidden from the AI schema.
+	Model         string `json:"model,omitempty" jsonschema:"-"`
+	PromptVersion string `json:"prompt_version,omitempty" jsonschema:"-"`
 }
 
 // TotalMacros computes total macros by summing all ingredient macros
@@ -82,24 +171,53 @@ func (s *ScanOutput) TotalMacros() MacroData {
 		totals.Carbs += ing.Carbs
 		totals.Fat += ing.Fat
 		totals.Fiber += ing.Fiber
+		totals.Nutrients = totals.Nutrients.Add(NewNutrientValues(ing.Nutrients))
 	}
 	// Round floats to 1 decimal place for clean output
 	totals.Protein = math.Round(totals.Protein*10) / 10
 	totals.Carbs = math.Round(totals.Carbs*10) / 10
 	totals.Fat = math.Round(totals.Fat*10) / 10
 	totals.Fiber = math.Round(totals.Fiber*10) / 10
+	totals.Nutrients = totals.Nutrients.rounded()
 	return totals
 }
 
-// TotalWeight computes total weight by summing all ingredient weights
+// CalorieBand is the uncertainty of the total calories derived from the ingredient weight ranges
+type CalorieBand struct {
+	Low  int
+	High int
+	// Uncertainty is the half width of the band, e.g. 80 for "520 ± 80 kcal"
+	Uncertainty int
+}
+
+// CalorieBand sums the calorie ranges of the ingredients. The weight estimates of the ingredients
+// are assumed to err independently, so their half widths are combined as root sum of squares
+// instead of adding up to an overly wide band.
+func (s *ScanOutput) CalorieBand() CalorieBand {
+	var total, squares float64
+	for i := range s.Ingredients {
+		low, high := s.Ingredients[i].CalorieRange()
+		total += float64(s.Ingredients[i].Calories)
+		squares += math.Pow((high-low)/2, 2)
+	}
+	uncertainty := math.Sqrt(squares)
+	return CalorieBand{
+		Low:         int(math.Round(math.Max(total-uncertainty, 0))),
+		High:        int(math.Round(total + uncertainty)),
+		Uncertainty: int(math.Round(uncertainty)),
+	}
+}
+
+// TotalWeight computes the total weight in grams by summing all ingredient weights. Ingredients
+// without a weight, e.g. servings of counted units like slices, are left out.
 func (s *ScanOutput) TotalWeight() int {
-	total := 0
-	for _, ing := range s.Ingredients {
-		// Weight removed from model, could sum serving size if units are standard.
-		// For now, return 0 as weight is not available.
-		_ = ing // Avoid unused variable warning
+	total := 0.0
+	for i := range s.Ingredients {
+		if grams, ok := s.Ingredients[i].Weight(); ok {
+			total += grams
+		}
 	}
-	return total
+	return int(math.Round(total))
 }
 
 // LogValue implements slog.LogValuer for structured logging
@@ -109,18 +227,87 @@ func (s *ScanOutput) LogValue() slog.Value {
 		slog.String("detected_object", s.DetectedObject),
 		slog.String("food_name", s.FoodName),
 		slog.Float64("confidence", s.Confidence),
+		slog.Bool("needs_clarification", s.NeedsClarification),
+		slog.Any("clarifying_questions", s.ClarifyingQuestions),
+		slog.Any("alternatives", s.Alternatives),
+		slog.String("model", s.Model),
+		slog.String("prompt_version", s.PromptVersion),
 		slog.Int("total_weight", s.TotalWeight()),
 		slog.Any("total_macros", s.TotalMacros()),
 		slog.Any("ingredients", s.Ingredients),
 	)
 }
 
+// ScanProgressStage identifies the kind of progress reported while a scan is streamed
+type ScanProgressStage string
+
+const (
+	// ScanStageAnalysing is reported when the model starts the analysis of the image. It is reported
+	// again when the analysis is restarted (retry or fallback model), which discards previously
+	// reported ingredients.
+	ScanStageAnalysing ScanProgressStage = "analysing"
+	// ScanStageIngredient is reported for every ingredient once the model has fully described it
+	ScanStageIngredient ScanProgressStage = "ingredient"
+)
+
+// ScanProgress is streamed by the food scan flow while the model response arrives
+type ScanProgress struct {
+	Stage      ScanProgressStage `json:"stage"`
+	Model      string            `json:"model,omitempty"`
+	Ingredient *Ingredient       `json:"ingredient,omitempty"`
+}
+
+// LogValue implements slog.LogValuer for structured logging
+func (p *ScanProgress) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("stage", string(p.Stage))}
+	if p.Model != "" {
+		attrs = append(attrs, slog.String("model", p.Model))
+	}
+	if p.Ingredient != nil {
+		attrs = append(attrs, slog.String("ingredient", p.Ingredient.Name))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// ScanProgressFunc receives the progress of a streamed scan. Returning an error aborts the scan.
+type ScanProgressFunc func(ctx context.Context, progress *ScanProgress) error
+
 type MacroData struct {
 	Calories int     `json:"calories"`
 	Protein  float64 `json:"protein"`
 	Carbs    float64 `json:"carbs"`
 	Fat      float64 `json:"fat"`
 	Fiber    float64 `json:"fiber"`
+	// Nutrients holds the catalog nutrients beyond the macros, keyed by nutrient ID
+	Nutrients NutrientValues `json:"nutrients,omitempty"`
+}
+
+// Add adds the other macros and nutrients
+func (m *MacroData) Add(other MacroData) {
+	m.Calories += other.Calories
+	m.Protein += other.Protein
+	m.Carbs += other.Carbs
+	m.Fat += other.Fat
+	m.Fiber += other.Fiber
+	m.Nutrients = m.Nutrients.Add(other.Nutrients)
+}
+
+// scaled returns the macros and nutrients multiplied by factor, e.g. grams/100 for macros per 100 g
+func (m *MacroData) scaled(factor float64) MacroData {
+	scaled := MacroData{
+		Calories: int(math.Round(float64(m.Calories) * factor)),
+		Protein:  round1(m.Protein * factor),
+		Carbs:    round1(m.Carbs * factor),
+		Fat:      round1(m.Fat * factor),
+		Fiber:    round1(m.Fiber * factor),
+	}
+	for id, amount := range m.Nutrients {
+		if scaled.Nutrients == nil {
+			scaled.Nutrients = NutrientValues{}
+		}
+		scaled.Nutrients[id] = roundNutrient(id, amount*factor)
+	}
+	return scaled
 }
 
 // LogValue implements slog.LogValuer for structured logging
@@ -131,16 +318,18 @@ func (m *MacroData) LogValue() slog.Value {
 		slog.Float64("carbs", m.Carbs),
 		slog.Float64("fat", m.Fat),
 		slog.Float64("fiber", m.Fiber),
+		slog.Any("nutrients", m.Nutrients),
 	)
 }
 
 // LogFoodInput represents the request to log a specific food
 type LogFoodInput struct {
-	UserID      *string
-	FoodName    string
-	Confidence  float64
-	Macros      MacroData
-	Ingredients []Ingredient
+	UserID        *string
+	FoodName      string
+	Confidence    float64
+	Macros        MacroData
+	Ingredients   []Ingredient
+	PromptVersion string
 }
 
 // LogFoodOutput represents the log response
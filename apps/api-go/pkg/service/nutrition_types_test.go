@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestCalorieBand(t *testing.T) {
+	tests := []struct {
+		name        string
+		ingredients []Ingredient
+		want        CalorieBand
+	}{
+		{name: "no ingredients", want: CalorieBand{}},
+		{
+			name:        "single ingredient",
+			ingredients: []Ingredient{{Calories: 200, Grams: 100, GramsLow: 80, GramsHigh: 120}},
+			want:        CalorieBand{Low: 160, High: 240, Uncertainty: 40},
+		},
+		{
+			// Half widths 30 and 40 combine to 50 instead of adding up to 70
+			name: "root sum of squares",
+			ingredients: []Ingredient{
+				{Calories: 300, Grams: 100, GramsLow: 90, GramsHigh: 110},
+				{Calories: 200, Grams: 50, GramsLow: 40, GramsHigh: 60},
+			},
+			want: CalorieBand{Low: 450, High: 550, Uncertainty: 50},
+		},
+		{
+			name: "ingredient without weight range",
+			ingredients: []Ingredient{
+				{Calories: 100, Grams: 100, GramsLow: 50, GramsHigh: 150},
+				{Calories: 50},
+			},
+			want: CalorieBand{Low: 100, High: 200, Uncertainty: 50},
+		},
+		{
+			name:        "inverted weight range",
+			ingredients: []Ingredient{{Calories: 100, Grams: 100, GramsLow: 150, GramsHigh: 50}},
+			want:        CalorieBand{Low: 100, High: 100},
+		},
+		{
+			name:        "never below zero",
+			ingredients: []Ingredient{{Calories: 10, Grams: 10, GramsLow: 1, GramsHigh: 100}},
+			want:        CalorieBand{Low: 0, High: 60, Uncertainty: 50},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &ScanOutput{Ingredients: tt.ingredients}
+			if got := output.CalorieBand(); got != tt.want {
+				t.Errorf("CalorieBand() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/dogab/vitalstack/api/internal/models"
+	"github.com/dogab/vitalstack/api/internal/repository"
+	"github.com/dogab/vitalstack/api/pkg/types"
+	"github.com/dogab/vitalstack/api/pkg/units"
+)
+
+// maxBodyMeasure bounds the body weight in kg and the height in cm, the columns hold up to 999.99
+const maxBodyMeasure = 1000
+
+// Profile is the profile of a user
+type Profile struct {
+	ID string `json:"id"`
+	// Weight is the body weight in kg and Height the body height in cm
+	Weight *float64 `json:"weight,omitempty"`
+	Height *float64 `json:"height,omitempty"`
+	Age    *int     `json:"age,omitempty"`
+	// Units is the measurement system quantities are rendered in for the user
+	Units units.System `json:"units"`
+}
+
+// UpdateProfileInput represents the changes to a profile, nil fields are kept
+type UpdateProfileInput struct {
+	UserID string
+	// Weight is the body weight in kg and Height the body height in cm
+	Weight *float64
+	Height *float64
+	Age    *int
+	Units  *units.System
+}
+
+// ProfileService manages the profiles of users
+type ProfileService struct {
+	userRepo repository.UserRepository
+}
+
+// NewProfileService creates a new profile service
+func NewProfileService(userRepo repository.UserRepository) *ProfileService {
+	return &ProfileService{userRepo: userRepo}
+}
+
+// GetProfile returns the profile of the user. Users without a profile get an empty metric profile.
+func (s *ProfileService) GetProfile(ctx context.Context, userID string) (*Profile, error) {
+	dbProfile, err := s.userRepo.GetProfile(ctx, userID)
+	if errors.Is(err, repository.ErrProfileNotFound) {
+		return &Profile{ID: userID, Units: units.Metric}, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get profile", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	return newProfile(dbProfile), nil
+}
+
+// GetUnits returns the measurement system preferred by the user
+func (s *ProfileService) GetUnits(ctx context.Context, userID string) (units.System, error) {
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return profile.Units, nil
+}
+
+// UpdateProfile applies the changes to the profile of the user, creating the profile if necessary
+func (s *ProfileService) UpdateProfile(ctx context.Context, input *UpdateProfileInput) (*Profile, error) {
+	if input.Weight != nil && (*input.Weight <= 0 || *input.Weight >= maxBodyMeasure) {
+		return nil, types.NewValidationError("weight must be a plausible body weight", "weight", "body", *input.Weight)
+	}
+	if input.Height != nil && (*input.Height <= 0 || *input.Height >= maxBodyMeasure) {
+		return nil, types.NewValidationError("height must be a plausible body height", "height", "body", *input.Height)
+	}
+	if input.Age != nil && (*input.Age <= 0 || *input.Age > 150) {
+		return nil, types.NewValidationError("age must be a plausible age", "age", "body", *input.Age)
+	}
+	var system units.System
+	if input.Units != nil {
+		var ok bool
+		if system, ok = units.ParseSystem(string(*input.Units)); !ok {
+			return nil, types.NewValidationError("units must be metric or imperial", "units", "body", *input.Units)
+		}
+	}
+
+	dbProfile, err := s.userRepo.GetProfile(ctx, input.UserID)
+	exists := err == nil
+	if errors.Is(err, repository.ErrProfileNotFound) {
+		dbProfile = &models.Profile{ID: input.UserID, Units: string(units.Metric)}
+	} else if err != nil {
+		slog.ErrorContext(ctx, "Failed to get profile", "user_id", input.UserID, "error", err)
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	if input.Weight != nil {
+		dbProfile.Weight = input.Weight
+	}
+	if input.Height != nil {
+		dbProfile.Height = input.Height
+	}
+	if input.Age != nil {
+		dbProfile.Age = input.Age
+	}
+	if system != "" {
+		dbProfile.Units = string(system)
+	}
+
+	if exists {
+		err = s.userRepo.UpdateProfile(ctx, dbProfile)
+	} else {
+		err = s.userRepo.CreateProfile(ctx, dbProfile)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save profile", "user_id", input.UserID, "error", err)
+		return nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+	return newProfile(dbProfile), nil
+}
+
+// newProfile maps the database profile, profiles without a known measurement system are metric
+func newProfile(p *models.Profile) *Profile {
+	system, ok := units.ParseSystem(p.Units)
+	if !ok {
+		system = units.Metric
+	}
+	return &Profile{
+		ID:     p.ID,
+		Weight: p.Weight,
+		Height: p.Height,
+		Age:    p.Age,
+		Units:  system,
+	}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
+
+	"github.com/dogab/vitalstack/api/prompts"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/google/dotprompt/go/dotprompt"
+)
+
+const (
+	// FoodScanPromptName is the name of the food scan prompt. Variants are stored as foodScan.<variant>.prompt.
+	FoodScanPromptName = "foodScan"
+	// NutritionLabelPromptName is the name of the nutrition label prompt
+	NutritionLabelPromptName = "nutritionLabel"
+	// DefaultPromptVariant selects the prompt without variant suffix, e.g. to A/B test it against a variant
+	DefaultPromptVariant = "default"
+)
+
+// ScanPrompt is a loaded prompt of a scan flow, e.g. a variant of the food scan prompt
+type ScanPrompt struct {
+	// Name is the registered prompt name including the variant, e.g. "foodScan.concise"
+	Name string
+	// Version identifies the prompt revision. It is taken from the version field of the
+	// frontmatter, or derived from the content if the field is missing.
+	Version string
+
+	prompt ai.Prompt
+}
+
+// LogValue implements slog.LogValuer for structured logging
+func (p ScanPrompt) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", p.Name),
+		slog.String("version", p.Version),
+	)
+}
+
+// scanPromptInput is the input of the food scan and nutrition label prompt templates
+type scanPromptInput struct {
+	ImageURL    string `json:"imageUrl"`
+	MimeType    string `json:"mimeType"`
+	Description string `json:"description,omitempty"`
+	// Nutrients is the nutrient catalog, so that nutrients are added without changing the prompts
+	Nutrients []Nutrient `json:"nutrients"`
+}
+
+// LoadScanPrompts loads the given variants of the food scan prompt from fsys into Genkit.
+// The default variant or no variants at all load the default prompt (foodScan.prompt).
+func LoadScanPrompts(g *genkit.Genkit, fsys fs.FS, variants ...string) ([]ScanPrompt, error) {
+	if len(variants) == 0 {
+		variants = []string{""}
+	}
+
+	scanPrompts := make([]ScanPrompt, 0, len(variants))
+	for _, variant := range variants {
+		name := FoodScanPromptName
+		if variant != "" && variant != DefaultPromptVariant {
+			name += "." + variant
+		}
+
+		scanPrompt, err := loadPrompt(g, fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("loaded food scan prompt", "prompt", scanPrompt)
+		scanPrompts = append(scanPrompts, scanPrompt)
+	}
+
+	return scanPrompts, nil
+}
+
+// LoadLabelPrompt loads the nutrition label prompt from fsys into Genkit
+func LoadLabelPrompt(g *genkit.Genkit, fsys fs.FS) (ScanPrompt, error) {
+	labelPrompt, err := loadPrompt(g, fsys, NutritionLabelPromptName)
+	if err != nil {
+		return ScanPrompt{}, err
+	}
+	slog.Info("loaded nutrition label prompt", "prompt", labelPrompt)
+	return labelPrompt, nil
+}
+
+// loadPrompt reads the prompt name.prompt of fsys and registers it in Genkit
+func loadPrompt(g *genkit.Genkit, fsys fs.FS, name string) (ScanPrompt, error) {
+	source, err := fs.ReadFile(fsys, name+".prompt")
+	if err != nil {
+		return ScanPrompt{}, fmt.Errorf("failed to read prompt %q: %w", name, err)
+	}
+
+	parsed, err := dotprompt.ParseDocument(string(source))
+	if err != nil {
+		return ScanPrompt{}, fmt.Errorf("failed to parse prompt %q: %w", name, err)
+	}
+
+	sum := sha256.Sum256(source)
+	version := parsed.Version
+	if version == "" {
+		version = name + "@" + hex.EncodeToString(sum[:4])
+	}
+
+	// Prompts are registered once per Genkit instance, e.g. when several services share it. The
+	// registered name contains the hash of the source, so that services loading another revision of
+	// the prompt (e.g. from another prompt directory) do not run the one registered first.
+	registeredName := name + "@" + hex.EncodeToString(sum[:8])
+	prompt := genkit.LookupPrompt(g, registeredName)
+	if prompt == nil {
+		prompt, err = genkit.LoadPromptFromSource(g, string(source), registeredName, "")
+		if err != nil {
+			return ScanPrompt{}, fmt.Errorf("failed to load prompt %q: %w", name, err)
+		}
+	}
+
+	return ScanPrompt{Name: name, Version: version, prompt: prompt}, nil
+}
+
+// mustLoadDefaultLabelPrompt loads the nutrition label prompt embedded in the binary
+func mustLoadDefaultLabelPrompt(g *genkit.Genkit) ScanPrompt {
+	labelPrompt, err := LoadLabelPrompt(g, prompts.FS)
+	if err != nil {
+		panic(fmt.Errorf("failed to load embedded prompts: %w", err))
+	}
+	return labelPrompt
+}
+
+// mustLoadDefaultScanPrompts loads the food scan prompt embedded in the binary
+func mustLoadDefaultScanPrompts(g *genkit.Genkit) []ScanPrompt {
+	scanPrompts, err := LoadScanPrompts(g, prompts.FS)
+	if err != nil {
+		panic(fmt.Errorf("failed to load embedded prompts: %w", err))
+	}
+	return scanPrompts
+}
+
+// isLoadedPromptVersion reports whether a loaded food scan or nutrition label prompt has the version
+func (s *NutritionService) isLoadedPromptVersion(version string) bool {
+	if version == s.labelPrompt.Version {
+		return true
+	}
+	for _, p := range s.scanPrompts {
+		if p.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// selectScanPrompt picks the prompt variant for a scan. With several variants every scan is
+// assigned one at random, so that their accuracy can be compared (A/B testing).
+func (s *NutritionService) selectScanPrompt() ScanPrompt {
+	if len(s.scanPrompts) == 1 {
+		return s.scanPrompts[0]
+	}
+	//nolint:gosec // variant assignment does not need a cryptographically secure source
+	return s.scanPrompts[rand.IntN(len(s.scanPrompts))]
+}
+
+// scanPrompt returns the loaded prompt with the given name, or the first one if it is unknown
+func (s *NutritionService) scanPrompt(name string) ScanPrompt {
+	for _, p := range s.scanPrompts {
+		if p.Name == name {
+			return p
+		}
+	}
+	return s.scanPrompts[0]
+}
+
+// renderScanPrompt renders the food scan prompt for the input into generate options
+func (s *NutritionService) renderScanPrompt(ctx context.Context, input *ScanInput) ([]ai.GenerateOption, error) {
+	return s.renderPrompt(ctx, s.scanPrompt(input.Prompt), input)
+}
+
+// renderPrompt renders the prompt of a scan flow for the input into generate options
+func (s *NutritionService) renderPrompt(ctx context.Context, scanPrompt ScanPrompt, input *ScanInput) ([]ai.GenerateOption, error) {
+	mimeType := input.MimeType()
+	promptInput := scanPromptInput{
+		ImageURL:  "data:" + mimeType + ";base64," + input.ImageBase64,
+		MimeType:  mimeType,
+		Nutrients: NutrientCatalog,
+	}
+	if input.Description != nil {
+		promptInput.Description = *input.Description
+	}
+
+	rendered, err := scanPrompt.prompt.Render(ctx, promptInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render prompt %q: %w", scanPrompt.Name, err)
+	}
+
+	opts := []ai.GenerateOption{ai.WithMessages(rendered.Messages...)}
+	// The configured generation config takes precedence over the config of the prompt file
+	switch {
+	case s.generationConfig != nil:
+		opts = append(opts, ai.WithConfig(s.generationConfig))
+	case rendered.Config != nil:
+		opts = append(opts, ai.WithConfig(rendered.Config))
+	}
+	return opts, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dogab/vitalstack/api/internal/aiprovider"
+	"github.com/dogab/vitalstack/api/pkg/types"
+)
+
+// RetryPolicy configures how model calls failing with a retryable error are retried
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per model including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponentially growing delay
+	MaxBackoff time.Duration
+	// Multiplier is applied to the delay after every retry
+	Multiplier float64
+	// Jitter is the fraction (0-1) of the delay which is randomized to spread out retries of concurrent requests
+	Jitter float64
+}
+
+// DefaultRetryPolicy is used if no retry policy is configured
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// LogValue implements slog.LogValuer for structured logging
+func (p RetryPolicy) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("max_attempts", p.MaxAttempts),
+		slog.Duration("initial_backoff", p.InitialBackoff),
+		slog.Duration("max_backoff", p.MaxBackoff),
+		slog.Float64("multiplier", p.Multiplier),
+		slog.Float64("jitter", p.Jitter),
+	)
+}
+
+// backoff returns the delay before the given retry (1 for the first retry)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(math.Max(p.Multiplier, 1), float64(retry-1))
+	if p.MaxBackoff > 0 {
+		delay = math.Min(delay, float64(p.MaxBackoff))
+	}
+	if p.Jitter > 0 {
+		//nolint:gosec // jitter does not need a cryptographically secure source
+		delay -= delay * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// generateFunc performs a single model call (e.g. a flow run) against the given model.
+// An empty model name uses the default model of Genkit.
+type generateFunc[T any] func(ctx context.Context, model string) (*T, error)
+
+// generateWithFallback calls generate for each configured model in order until one succeeds.
+// Retryable errors are retried on the same model according to the retry policy before
+// falling back to the next model. It returns the result and the model which produced it, or a
+// service unavailable error if the models are unavailable (see WithModelUnavailable).
+func generateWithFallback[T any](ctx context.Context, s *NutritionService, generate generateFunc[T]) (*T, string, error) {
+	if s.modelUnavailable != nil {
+		slog.WarnContext(ctx, "model call rejected, the AI model is unavailable", "reason", s.modelUnavailable)
+		return nil, "", types.NewServiceUnavailableError(fmt.Sprintf("the AI model is unavailable: %s", s.modelUnavailable))
+	}
+
+	models := s.models
+	if len(models) == 0 {
+		models = []string{""}
+	}
+
+	var errs []error
+	for i, model := range models {
+		result, err := generateWithRetry(ctx, s.retryPolicy, model, generate)
+		if err == nil {
+			if i > 0 {
+				slog.WarnContext(ctx, "model call succeeded on fallback model", "model", model, "failed_models", models[:i])
+			}
+			return result, model, nil
+		}
+
+		errs = append(errs, fmt.Errorf("model %q: %w", model, err))
+
+		// Do not try further models if the request was canceled or timed out
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(models)-1 {
+			slog.WarnContext(ctx, "model call failed, falling back to next model", "model", model, "next_model", models[i+1], "error", err)
+		}
+	}
+
+	return nil, "", errors.Join(errs...)
+}
+
+// generateWithRetry calls generate for a single model and retries retryable errors with exponential backoff
+func generateWithRetry[T any](ctx context.Context, policy RetryPolicy, model string, generate generateFunc[T]) (*T, error) {
+	attempts := max(policy.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		result, err := generate(ctx, model)
+		if err == nil {
+			return result, nil
+		}
+
+		if attempt >= attempts || !aiprovider.IsRetryable(err) {
+			return nil, err
+		}
+
+		delay := policy.backoff(attempt)
+		slog.WarnContext(ctx, "retrying model call after transient error",
+			"model", model, "attempt", attempt, "max_attempts", attempts, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 500 * time.Millisecond, MaxBackoff: 3 * time.Second, Multiplier: 2}
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{name: "first retry", policy: policy, retry: 1, want: 500 * time.Millisecond},
+		{name: "second retry", policy: policy, retry: 2, want: time.Second},
+		{name: "third retry", policy: policy, retry: 3, want: 2 * time.Second},
+		{name: "capped", policy: policy, retry: 4, want: 3 * time.Second},
+		{name: "uncapped", policy: RetryPolicy{InitialBackoff: time.Second, Multiplier: 2}, retry: 5, want: 16 * time.Second},
+		{name: "multiplier below 1", policy: RetryPolicy{InitialBackoff: time.Second, Multiplier: 0.5}, retry: 3, want: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.backoff(tt.retry); got != tt.want {
+				t.Errorf("backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	tests := []struct {
+		name   string
+		jitter float64
+		min    time.Duration
+	}{
+		{name: "jitter", jitter: 0.2, min: 800 * time.Millisecond},
+		// Jitter above 1 is capped, the delay never becomes negative
+		{name: "jitter above 1", jitter: 5, min: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := RetryPolicy{InitialBackoff: time.Second, Multiplier: 2, Jitter: tt.jitter}
+			for range 100 {
+				if got := policy.backoff(1); got < tt.min || got > time.Second {
+					t.Fatalf("backoff(1) = %v, want between %v and %v", got, tt.min, time.Second)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// scanCacheKeyPrefix namespaces scan results in caches shared with other data
+const scanCacheKeyPrefix = "scan:"
+
+// CacheStats holds the number of scan cache lookups by outcome
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// LogValue implements slog.LogValuer for structured logging
+func (c CacheStats) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("hits", c.Hits),
+		slog.Int64("misses", c.Misses),
+	)
+}
+
+// scanCacheCounters counts scan cache lookups
+type scanCacheCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// scanCacheKey derives the cache key of a scan from the prompt version, the decoded image and the description
+func scanCacheKey(image []byte, description *string, promptVersion string) string {
+	h := sha256.New()
+	h.Write([]byte(promptVersion))
+	h.Write([]byte{0})
+	h.Write(image)
+	if description != nil {
+		// Separate the image from the description so that their boundary is unambiguous
+		h.Write([]byte{0})
+		h.Write([]byte(strings.TrimSpace(*description)))
+	}
+	return scanCacheKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// CacheStats returns the scan cache hit and miss counts since the service was created
+func (s *NutritionService) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   s.cacheCounters.hits.Load(),
+		Misses: s.cacheCounters.misses.Load(),
+	}
+}
+
+// getCachedScan returns the cached scan output for key. Cache failures are logged
+// and treated as a miss so that scans keep working without the cache.
+func (s *NutritionService) getCachedScan(ctx context.Context, key string) (*ScanOutput, bool) {
+	if s.scanCache == nil {
+		return nil, false
+	}
+
+	data, found, err := s.scanCache.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read scan cache", "error", err, "key", key)
+		found = false
+	}
+
+	var output ScanOutput
+	if found {
+		if err := json.Unmarshal(data, &output); err != nil {
+			slog.WarnContext(ctx, "failed to decode cached scan", "error", err, "key", key)
+			found = false
+		}
+	}
+
+	if !found {
+		s.cacheCounters.misses.Add(1)
+		s.recordCacheLookup(false)
+		slog.InfoContext(ctx, "scan cache miss", "key", key, "stats", s.CacheStats())
+		return nil, false
+	}
+
+	s.cacheCounters.hits.Add(1)
+	s.recordCacheLookup(true)
+	slog.InfoContext(ctx, "scan cache hit", "key", key, "stats", s.CacheStats())
+	return &output, true
+}
+
+// setCachedScan stores the scan output for key. Failures are logged and otherwise ignored.
+func (s *NutritionService) setCachedScan(ctx context.Context, key string, output *ScanOutput) {
+	if s.scanCache == nil {
+		return
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to encode scan for cache", "error", err, "key", key)
+		return
+	}
+
+	if err := s.scanCache.Set(ctx, key, data, s.scanCacheTTL); err != nil {
+		slog.WarnContext(ctx, "failed to write scan cache", "error", err, "key", key)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// streamScan generates the scan output with a streamed model response. The partial JSON of the
+// response is parsed with every chunk, and all but the last ingredient of a partial response are
+// complete, so each ingredient is sent as soon as the model starts describing the next one.
+func (s *NutritionService) streamScan(ctx context.Context, input *ScanInput, sendChunk core.StreamCallback[*ScanProgress], opts ...ai.GenerateOption) (*ScanOutput, error) {
+	if err := sendChunk(ctx, &ScanProgress{Stage: ScanStageAnalysing, Model: input.Model}); err != nil {
+		return nil, err
+	}
+
+	sent := 0
+	for value, err := range genkit.GenerateDataStream[ScanOutput](ctx, s.genkit, opts...) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze food image: %w", err)
+		}
+
+		ingredients := value.Chunk.Ingredients
+		if value.Done {
+			ingredients = value.Output.Ingredients
+		} else if len(ingredients) > 0 {
+			ingredients = ingredients[:len(ingredients)-1]
+		}
+
+		for ; sent < len(ingredients); sent++ {
+			if err := sendChunk(ctx, &ScanProgress{Stage: ScanStageIngredient, Ingredient: &ingredients[sent]}); err != nil {
+				return nil, err
+			}
+		}
+
+		if value.Done {
+			s.recordUsage(FoodScanFlow, input.Model, value.Response)
+			return &value.Output, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to analyze food image: stream ended without a result")
+}
+
+// streamFlow runs the food scan flow with streaming and passes its progress to onProgress
+func streamFlow(ctx context.Context, flow *core.Flow[*ScanInput, *ScanOutput, *ScanProgress], input *ScanInput, onProgress ScanProgressFunc) (*ScanOutput, error) {
+	var output *ScanOutput
+	var streamErr error
+	flow.Stream(ctx, input)(func(value *core.StreamingFlowValue[*ScanOutput, *ScanProgress], err error) bool {
+		switch {
+		case streamErr != nil:
+			// The flow reports the abort caused by a failed progress report, keep the original error
+		case err != nil:
+			streamErr = err
+		case value.Done:
+			output = value.Output
+		default:
+			if err := onProgress(ctx, value.Stream); err != nil {
+				// Not wrapped, write errors of the client connection must not be retried
+				streamErr = fmt.Errorf("failed to report scan progress: %v", err)
+			}
+		}
+		return streamErr == nil
+	})
+	return output, streamErr
+}
+
+// reportIngredients reports a scan result which was not streamed (e.g. cached or mocked)
+// as progress, so that streaming clients receive the same events as for generated results
+func reportIngredients(ctx context.Context, onProgress ScanProgressFunc, output *ScanOutput) error {
+	if onProgress == nil {
+		return nil
+	}
+
+	if err := onProgress(ctx, &ScanProgress{Stage: ScanStageAnalysing, Model: output.Model}); err != nil {
+		return fmt.Errorf("failed to report scan progress: %v", err)
+	}
+	for i := range output.Ingredients {
+		if err := onProgress(ctx, &ScanProgress{Stage: ScanStageIngredient, Ingredient: &output.Ingredients[i]}); err != nil {
+			return fmt.Errorf("failed to report scan progress: %v", err)
+		}
+	}
+	return nil
+}
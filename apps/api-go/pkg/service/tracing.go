@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the service spans
+const tracerName = "github.com/dogab/vitalstack/api/pkg/service"
+
+// traced runs the call in a span named after the service method. The spans of the Genkit flows and
+// repository calls made by the method are nested under it, failures are recorded on the span.
+func traced[T any](ctx context.Context, name string, call func(ctx context.Context) (T, error), attrs ...attribute.KeyValue) (T, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+	defer span.End()
+
+	result, err := call(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return result, err
+}
+
+// tracedErr runs a call returning only an error like traced
+func tracedErr(ctx context.Context, name string, call func(ctx context.Context) error, attrs ...attribute.KeyValue) error {
+	_, err := traced(ctx, name, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, call(ctx)
+	}, attrs...)
+	return err
+}
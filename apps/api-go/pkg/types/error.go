@@ -109,3 +109,111 @@ func (n *NotFoundError) HTTPStatus() int {
 func (n *NotFoundError) Type() string {
 	return "NOT_FOUND_ERROR"
 }
+
+// PayloadTooLargeError represents an error for request payloads exceeding a size limit
+type PayloadTooLargeError struct {
+	Message string
+}
+
+// NewPayloadTooLargeError creates a new payload too large error
+func NewPayloadTooLargeError(message string) *PayloadTooLargeError {
+	return &PayloadTooLargeError{
+		Message: message,
+	}
+}
+
+// Error implements error interface
+func (p *PayloadTooLargeError) Error() string {
+	return p.Message
+}
+
+// HTTPStatus returns the HTTP status code for the error
+func (p *PayloadTooLargeError) HTTPStatus() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// Type returns the type of the error
+func (p *PayloadTooLargeError) Type() string {
+	return "PAYLOAD_TOO_LARGE_ERROR"
+}
+
+// GatewayTimeoutError represents an error for upstream calls (e.g. AI models) which did not complete in time
+type GatewayTimeoutError struct {
+	Message string
+}
+
+// NewGatewayTimeoutError creates a new gateway timeout error
+func NewGatewayTimeoutError(message string) *GatewayTimeoutError {
+	return &GatewayTimeoutError{
+		Message: message,
+	}
+}
+
+// Error implements error interface
+func (g *GatewayTimeoutError) Error() string {
+	return g.Message
+}
+
+// HTTPStatus returns the HTTP status code for the error
+func (g *GatewayTimeoutError) HTTPStatus() int {
+	return http.StatusGatewayTimeout
+}
+
+// Type returns the type of the error
+func (g *GatewayTimeoutError) Type() string {
+	return "GATEWAY_TIMEOUT_ERROR"
+}
+
+// BadGatewayError represents an error for upstream calls (e.g. product lookups) which failed
+type BadGatewayError struct {
+	Message string
+}
+
+// NewBadGatewayError creates a new bad gateway error
+func NewBadGatewayError(message string) *BadGatewayError {
+	return &BadGatewayError{
+		Message: message,
+	}
+}
+
+// Error implements error interface
+func (b *BadGatewayError) Error() string {
+	return b.Message
+}
+
+// HTTPStatus returns the HTTP status code for the error
+func (b *BadGatewayError) HTTPStatus() int {
+	return http.StatusBadGateway
+}
+
+// Type returns the type of the error
+func (b *BadGatewayError) Type() string {
+	return "BAD_GATEWAY_ERROR"
+}
+
+// ServiceUnavailableError represents an error for features whose dependencies (e.g. the AI provider) are unavailable
+type ServiceUnavailableError struct {
+	Message string
+}
+
+// NewServiceUnavailableError creates a new service unavailable error
+func NewServiceUnavailableError(message string) *ServiceUnavailableError {
+	return &ServiceUnavailableError{
+		Message: message,
+	}
+}
+
+// Error implements error interface
+func (s *ServiceUnavailableError) Error() string {
+	return s.Message
+}
+
+// HTTPStatus returns the HTTP status code for the error
+func (s *ServiceUnavailableError) HTTPStatus() int {
+	return http.StatusServiceUnavailable
+}
+
+// Type returns the type of the error
+func (s *ServiceUnavailableError) Type() string {
+	return "SERVICE_UNAVAILABLE_ERROR"
+}
@@ -0,0 +1,107 @@
+package units
+
+import (
+	"strings"
+	"unicode"
+)
+
+// waterDensity is the density in g/ml of foods without a known density
+const waterDensity = 1.0
+
+// densities are the densities in g/ml of foods commonly measured by volume. Foods match the first
+// keyword found as whole words of their name, in singular or plural, so compound names come before their
+// parts ("peanut butter" before "butter"). Densities of dry foods are bulk densities as measured with a cup.
+var densities = []struct {
+	keyword string
+	density float64
+}{
+	{"peanut butter", 1.08},
+	{"almond butter", 1.02},
+	{"powdered sugar", 0.56},
+	{"icing sugar", 0.56},
+	{"brown sugar", 0.93},
+	{"maple syrup", 1.32},
+	{"coconut milk", 0.97},
+	{"almond milk", 1.01},
+	{"oat milk", 1.03},
+	{"soy milk", 1.02},
+	{"cream cheese", 0.98},
+	{"sour cream", 1.01},
+	{"cottage cheese", 0.95},
+	{"whipped cream", 0.25},
+	{"ice cream", 0.55},
+	{"coconut water", 1.0},
+	{"chia", 0.68},
+	{"oil", 0.92},
+	{"butter", 0.96},
+	{"honey", 1.42},
+	{"syrup", 1.33},
+	{"sugar", 0.85},
+	{"flour", 0.53},
+	{"cocoa", 0.44},
+	{"oat", 0.38},
+	{"granola", 0.45},
+	{"cereal", 0.15},
+	{"rice", 0.79},
+	{"quinoa", 0.78},
+	{"pasta", 0.55},
+	{"lentil", 0.83},
+	{"bean", 0.77},
+	{"yogurt", 1.03},
+	{"yoghurt", 1.03},
+	{"cream", 1.0},
+	{"milk", 1.03},
+	{"parmesan", 0.42},
+	{"cheese", 0.47},
+	{"salsa", 1.06},
+	{"ketchup", 1.15},
+	{"mayonnaise", 0.91},
+	{"hummus", 1.05},
+	{"jam", 1.33},
+	{"nut", 0.6},
+	{"almond", 0.6},
+	{"berry", 0.6},
+	{"berries", 0.6},
+	{"salad", 0.2},
+	{"green", 0.2},
+	{"spinach", 0.13},
+	{"soup", 1.02},
+	{"juice", 1.05},
+	{"soda", 1.04},
+	{"cola", 1.04},
+	{"wine", 0.99},
+	{"beer", 1.01},
+	{"salt", 1.2},
+}
+
+// compoundKeywords also match at the end of a word ("blueberry", "walnut"). The other keywords only match
+// whole words, so that e.g. "goat cheese" does not match "oat" and "licorice" does not match "rice".
+var compoundKeywords = map[string]bool{
+	"flour":   true,
+	"bean":    true,
+	"milk":    true,
+	"nut":     true,
+	"berry":   true,
+	"berries": true,
+}
+
+// Density returns the density of the food in g/ml, estimated from its name. Foods without a known
+// density are assumed to be as dense as water.
+func Density(food string) float64 {
+	// Pad the words with spaces, so that keywords can be matched as whole words
+	name := " " + strings.Join(strings.FieldsFunc(strings.ToLower(food), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}), " ") + " "
+	for _, d := range densities {
+		prefix := " "
+		if compoundKeywords[d.keyword] {
+			prefix = ""
+		}
+		for _, suffix := range []string{" ", "s ", "es "} {
+			if strings.Contains(name, prefix+d.keyword+suffix) {
+				return d.density
+			}
+		}
+	}
+	return waterDensity
+}
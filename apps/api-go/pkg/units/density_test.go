@@ -0,0 +1,36 @@
+package units
+
+import (
+	"testing"
+)
+
+func TestDensity(t *testing.T) {
+	tests := []struct {
+		food string
+		want float64
+	}{
+		{"Olive Oil", 0.92},
+		{"Peanut Butter", 1.08},
+		{"Butter", 0.96},
+		{"Rolled Oats", 0.38},
+		{"Oat Milk", 1.03},
+		{"Honey", 1.42},
+		{"Blueberries", 0.6},
+		{"Walnuts", 0.6},
+		{"Buttermilk", 1.03},
+		{"Black Beans", 0.77},
+		{"Cherry Tomatoes", waterDensity},
+		// Keywords only match whole words unless they name compounds
+		{"Goat Cheese", 0.47},
+		{"Licorice", waterDensity},
+		{"Coated Nuts", 0.6},
+		{"Boiled Potatoes", waterDensity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.food, func(t *testing.T) {
+			if got := Density(tt.food); got != tt.want {
+				t.Errorf("Density(%q) = %v, want %v", tt.food, got, tt.want)
+			}
+		})
+	}
+}
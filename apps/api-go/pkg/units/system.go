@@ -0,0 +1,119 @@
+package units
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// System is a measurement system quantities are rendered in
+type System string
+
+const (
+	// Metric renders grams, milliliters, kilograms and centimeters
+	Metric System = "metric"
+	// Imperial renders ounces, fluid ounces, pounds and inches
+	Imperial System = "imperial"
+)
+
+var (
+	// imperialUnits are the units of the imperial system, all other units except the household
+	// measures are metric
+	imperialUnits = map[string]bool{"oz": true, "lb": true, "fl oz": true, "pt": true, "qt": true, "gal": true, "in": true}
+	// householdMeasures are used in both systems, they are never converted
+	householdMeasures = map[string]bool{"tsp": true, "tbsp": true, "cup": true}
+	// compactSymbols are written without a space after the amount, e.g. "350g"
+	compactSymbols = map[string]bool{"mg": true, "g": true, "kg": true, "ml": true, "cl": true, "dl": true, "l": true, "cm": true}
+)
+
+// ParseSystem returns the system named s, e.g. "metric" or "Imperial"
+func ParseSystem(s string) (System, bool) {
+	switch system := System(strings.ToLower(strings.TrimSpace(s))); system {
+	case Metric, Imperial:
+		return system, true
+	default:
+		return "", false
+	}
+}
+
+// Serving converts the amount of a serving unit to the system: masses and volumes of the other system
+// are converted to grams and milliliters (metric) or ounces and fluid ounces (imperial). Units of the
+// system and household measures are kept.
+func (s System) Serving(amount float64, u Unit) (float64, Unit) {
+	if householdMeasures[u.Symbol] || imperialUnits[u.Symbol] == (s == Imperial) {
+		return amount, u
+	}
+	target := s.baseUnit(u.Dimension)
+	return amount * u.Factor / target.Factor, target
+}
+
+// FoodWeight returns the food weight in grams in the system, in g or oz
+func (s System) FoodWeight(grams float64) (float64, Unit) {
+	return s.Serving(grams, Gram)
+}
+
+// BodyWeight returns the body weight in kg in the system, in kg or lb
+func (s System) BodyWeight(kg float64) (float64, Unit) {
+	if s == Imperial {
+		return kg * Kilogram.Factor / Pound.Factor, Pound
+	}
+	return kg, Kilogram
+}
+
+// BodyWeightKg converts the body weight in the unit of the system (kg or lb) to kg
+func (s System) BodyWeightKg(weight float64) float64 {
+	_, u := s.BodyWeight(weight)
+	return weight * u.Factor / Kilogram.Factor
+}
+
+// Height returns the body height in cm in the system, in cm or in
+func (s System) Height(cm float64) (float64, Unit) {
+	return s.Serving(cm, Centimeter)
+}
+
+// HeightCm converts the body height in the unit of the system (cm or in) to cm
+func (s System) HeightCm(height float64) float64 {
+	_, u := s.Height(height)
+	return height * u.Factor
+}
+
+// baseUnit is the unit amounts of the dimension are converted to in the system
+func (s System) baseUnit(d Dimension) Unit {
+	switch {
+	case d == Mass && s == Imperial:
+		return Ounce
+	case d == Mass:
+		return Gram
+	case d == Volume && s == Imperial:
+		return FluidOunce
+	case d == Volume:
+		return Milliliter
+	case s == Imperial:
+		return Inch
+	default:
+		return Centimeter
+	}
+}
+
+// Round rounds the amount to the precision the unit is written with: grams, milliliters and
+// centimeters are whole, household measures have two decimals and all other units one
+func Round(amount float64, u Unit) float64 {
+	decimals := 1.0
+	switch {
+	case u == Gram || u == Milligram || u == Milliliter || u == Centimeter:
+		decimals = 0
+	case householdMeasures[u.Symbol]:
+		decimals = 2
+	}
+	scale := math.Pow(10, decimals)
+	return math.Round(amount*scale) / scale
+}
+
+// Format formats the amount rounded to the precision of the unit, e.g. "350g" or "12.3 oz"
+func Format(amount float64, u Unit) string {
+	value := strconv.FormatFloat(Round(amount, u), 'f', -1, 64)
+	if compactSymbols[u.Symbol] {
+		return value + u.Symbol
+	}
+	return value + " " + u.Symbol
+}
@@ -0,0 +1,142 @@
+// Package units converts the units of serving sizes and renders quantities in a measurement system.
+//
+// Masses are normalized to grams and volumes to milliliters. Household measures like cups and spoons
+// are volumes, they are converted to grams with the density of the food (see Density). Counted units
+// like slices or pieces have no fixed weight and are not units of this package. System renders food
+// and body measures in metric or imperial units, for API responses as well as exports.
+package units
+
+import (
+	"strings"
+)
+
+// Dimension is the quantity a unit measures
+type Dimension int
+
+const (
+	// Mass units are converted to grams
+	Mass Dimension = iota
+	// Volume units are converted to milliliters
+	Volume
+	// Length units are converted to centimeters, they measure the body height
+	Length
+)
+
+// Unit is a unit of mass, volume or length
+type Unit struct {
+	// Symbol is the canonical abbreviation, e.g. "g" or "fl oz"
+	Symbol    string
+	Dimension Dimension
+	// Factor is the amount of the base unit of the dimension (g, ml or cm) in one unit
+	Factor float64
+}
+
+// Mass units
+var (
+	Milligram = Unit{"mg", Mass, 1e-3}
+	Gram      = Unit{"g", Mass, 1}
+	Kilogram  = Unit{"kg", Mass, 1e3}
+	Ounce     = Unit{"oz", Mass, 28.349523125}
+	Pound     = Unit{"lb", Mass, 453.59237}
+)
+
+// Volume units, household measures are US customary
+var (
+	Milliliter = Unit{"ml", Volume, 1}
+	Centiliter = Unit{"cl", Volume, 10}
+	Deciliter  = Unit{"dl", Volume, 100}
+	Liter      = Unit{"l", Volume, 1e3}
+	Teaspoon   = Unit{"tsp", Volume, 4.92892159375}
+	Tablespoon = Unit{"tbsp", Volume, 14.78676478125}
+	FluidOunce = Unit{"fl oz", Volume, 29.5735295625}
+	Cup        = Unit{"cup", Volume, 236.5882365}
+	Pint       = Unit{"pt", Volume, 473.176473}
+	Quart      = Unit{"qt", Volume, 946.352946}
+	Gallon     = Unit{"gal", Volume, 3785.411784}
+)
+
+// Length units
+var (
+	Centimeter = Unit{"cm", Length, 1}
+	Inch       = Unit{"in", Length, 2.54}
+)
+
+var (
+	allUnits = []Unit{
+		Milligram, Gram, Kilogram, Ounce, Pound,
+		Milliliter, Centiliter, Deciliter, Liter, Teaspoon, Tablespoon, FluidOunce, Cup, Pint, Quart, Gallon,
+		Centimeter, Inch,
+	}
+	// unitAliases are the names of the units besides their symbols, in singular
+	unitAliases = map[string][]string{
+		"mg":    {"milligram", "milligramme"},
+		"g":     {"gr", "gram", "gramme"},
+		"kg":    {"kilo", "kilogram", "kilogramme"},
+		"oz":    {"ounce"},
+		"lb":    {"lbs", "pound"},
+		"ml":    {"milliliter", "millilitre"},
+		"cl":    {"centiliter", "centilitre"},
+		"dl":    {"deciliter", "decilitre"},
+		"l":     {"liter", "litre"},
+		"tsp":   {"teaspoon"},
+		"tbsp":  {"tbs", "tbl", "tablespoon"},
+		"fl oz": {"floz", "fl ounce", "fluid ounce"},
+		"pt":    {"pint"},
+		"qt":    {"quart"},
+		"gal":   {"gallon"},
+		"cm":    {"centimeter", "centimetre"},
+		"in":    {"inch"},
+	}
+)
+
+// unitsByName maps the symbols and aliases to their units
+var unitsByName = func() map[string]Unit {
+	byName := make(map[string]Unit, len(allUnits)*3)
+	for _, u := range allUnits {
+		byName[u.Symbol] = u
+		for _, alias := range unitAliases[u.Symbol] {
+			byName[alias] = u
+		}
+	}
+	return byName
+}()
+
+// Parse returns the unit named s, e.g. "g", "Grams", "tbsp." or "fl. oz". Plurals and periods are
+// ignored. Counted units like "slice" are not known.
+func Parse(s string) (Unit, bool) {
+	name := strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(s, ".", " "))), " ")
+	if u, ok := unitsByName[name]; ok {
+		return u, true
+	}
+	if singular, ok := strings.CutSuffix(name, "s"); ok && len(singular) > 1 {
+		u, ok := unitsByName[singular]
+		return u, ok
+	}
+	return Unit{}, false
+}
+
+// Convert converts the amount from one unit to another unit of the same dimension. Use ToGrams to
+// convert volumes to masses.
+func Convert(amount float64, from, to Unit) (float64, bool) {
+	if from.Dimension != to.Dimension {
+		return 0, false
+	}
+	return amount * from.Factor / to.Factor, true
+}
+
+// ToGrams converts the amount of the food in the named unit to grams. Volumes are converted with the
+// density of the food. It fails for unknown and counted units.
+func ToGrams(amount float64, unit, food string) (float64, bool) {
+	u, ok := Parse(unit)
+	if !ok {
+		return 0, false
+	}
+	switch u.Dimension {
+	case Mass:
+		return amount * u.Factor, true
+	case Volume:
+		return amount * u.Factor * Density(food), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,72 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   Unit
+		wantOK bool
+	}{
+		{"g", Gram, true},
+		{"Grams", Gram, true},
+		{"gr", Gram, true},
+		{"KG", Kilogram, true},
+		{"oz", Ounce, true},
+		{"ounces", Ounce, true},
+		{"lbs", Pound, true},
+		{"ml", Milliliter, true},
+		{"Litres", Liter, true},
+		{"tbsp.", Tablespoon, true},
+		{"tbs", Tablespoon, true},
+		{"Teaspoons", Teaspoon, true},
+		{"fl. oz", FluidOunce, true},
+		{"fl  oz", FluidOunce, true},
+		{"fluid ounces", FluidOunce, true},
+		{"cups", Cup, true},
+		{"in", Inch, true},
+		{"slice", Unit{}, false},
+		{"pieces", Unit{}, false},
+		{"s", Unit{}, false},
+		{"", Unit{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.name)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Parse(%q) = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestToGrams(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount float64
+		unit   string
+		food   string
+		want   float64
+		wantOK bool
+	}{
+		{name: "grams", amount: 150, unit: "g", food: "Rice", want: 150, wantOK: true},
+		{name: "ounces", amount: 3, unit: "oz", food: "Chicken Breast", want: 85.048569375, wantOK: true},
+		{name: "milliliters of water", amount: 250, unit: "ml", food: "Water", want: 250, wantOK: true},
+		{name: "cup of milk", amount: 1, unit: "cup", food: "Whole Milk", want: 236.5882365 * 1.03, wantOK: true},
+		{name: "tablespoons of peanut butter", amount: 2, unit: "tbsp", food: "Peanut Butter", want: 2 * 14.78676478125 * 1.08, wantOK: true},
+		{name: "unknown food", amount: 100, unit: "ml", food: "Mystery Sauce", want: 100, wantOK: true},
+		{name: "counted unit", amount: 2, unit: "slices", food: "Bread"},
+		{name: "length", amount: 10, unit: "cm", food: "Baguette"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ToGrams(tt.amount, tt.unit, tt.food)
+			if math.Abs(got-tt.want) > 1e-9 || ok != tt.wantOK {
+				t.Errorf("ToGrams(%v, %q, %q) = %v, %v, want %v, %v", tt.amount, tt.unit, tt.food, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package usda
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// csvDataTypes are the data types of food.csv which are foods. The other rows are the samples and
+// acquisitions the foundation foods were analyzed from.
+var csvDataTypes = map[string]bool{
+	"foundation_food":   true,
+	"sr_legacy_food":    true,
+	"survey_fndds_food": true,
+	"branded_food":      true,
+}
+
+// readCSV reads the foods of an unzipped FDC CSV download. The mapped nutrient amounts of
+// food_nutrient.csv are loaded first, then the foods of food.csv are streamed. The categories
+// are read from food_category.csv if present.
+func readCSV(dir string, fn FoodFunc) error {
+	categories := map[string]string{}
+	err := readCSVFile(filepath.Join(dir, "food_category.csv"), []string{"id", "description"}, func(row []string) error {
+		categories[row[0]] = row[1]
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	amounts := map[string]map[int]float64{}
+	err = readCSVFile(filepath.Join(dir, "food_nutrient.csv"), []string{"fdc_id", "nutrient_id", "amount"}, func(row []string) error {
+		nutrientID, err := strconv.Atoi(row[1])
+		if err != nil || !isMappedNutrient(nutrientID) || row[2] == "" {
+			return nil
+		}
+		amount, err := strconv.ParseFloat(row[2], 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q of food %s", row[2], row[0])
+		}
+		if amounts[row[0]] == nil {
+			amounts[row[0]] = map[int]float64{}
+		}
+		amounts[row[0]][nutrientID] = amount
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	columns := []string{"fdc_id", "data_type", "description", "food_category_id"}
+	return readCSVFile(filepath.Join(dir, "food.csv"), columns, func(row []string) error {
+		if !csvDataTypes[row[1]] {
+			return nil
+		}
+		if f := newFood(row[0], row[2], categories[row[3]], amounts[row[0]]); f != nil {
+			return fn(f)
+		}
+		return nil
+	})
+}
+
+// readCSVFile calls fn with the given columns of every row of the CSV file
+func readCSVFile(path string, columns []string, fn func(row []string) error) error {
+	f, err := os.Open(path) //nolint:gosec // the download is provided by the operator
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read only
+
+	r := csv.NewReader(bufio.NewReader(f))
+	r.ReuseRecord = true
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("invalid CSV %q: %w", path, err)
+	}
+
+	indexes := make([]int, len(columns))
+	for i, column := range columns {
+		indexes[i] = -1
+		for j, name := range header {
+			if name == column {
+				indexes[i] = j
+			}
+		}
+		if indexes[i] < 0 {
+			return fmt.Errorf("invalid CSV %q: missing column %q", path, column)
+		}
+	}
+
+	row := make([]string, len(columns))
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid CSV %q: %w", path, err)
+		}
+		for i, index := range indexes {
+			row[i] = record[index]
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package usda
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// jsonFood is a food of the FDC JSON downloads. The category is stored differently per data type.
+type jsonFood struct {
+	FDCID        int    `json:"fdcId"`
+	Description  string `json:"description"`
+	FoodCategory *struct {
+		Description string `json:"description"`
+	} `json:"foodCategory"`
+	BrandedFoodCategory string `json:"brandedFoodCategory"`
+	WWEIAFoodCategory   *struct {
+		Description string `json:"wweiaFoodCategoryDescription"`
+	} `json:"wweiaFoodCategory"`
+	FoodNutrients []struct {
+		Nutrient struct {
+			ID int `json:"id"`
+		} `json:"nutrient"`
+		Amount *float64 `json:"amount"`
+	} `json:"foodNutrients"`
+}
+
+// category returns the category of the food for any data type
+func (f *jsonFood) category() string {
+	switch {
+	case f.FoodCategory != nil:
+		return f.FoodCategory.Description
+	case f.WWEIAFoodCategory != nil:
+		return f.WWEIAFoodCategory.Description
+	default:
+		return f.BrandedFoodCategory
+	}
+}
+
+// readJSON streams the foods of an FDC JSON download. The downloads wrap the foods in an object with
+// a single key per data type (e.g. {"FoundationFoods": [...]}), a plain array of foods is read as well.
+// The foods are decoded one by one, since the branded foods download is several gigabytes.
+func readJSON(path string, fn FoodFunc) error {
+	f, err := os.Open(path) //nolint:gosec // the download is provided by the operator
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read only
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid FoodData Central JSON %q: %w", path, err)
+	}
+
+	switch token {
+	case json.Delim('['):
+		return readJSONFoods(dec, fn)
+	case json.Delim('{'):
+		for dec.More() {
+			if _, err := dec.Token(); err != nil { // data type key
+				return fmt.Errorf("invalid FoodData Central JSON %q: %w", path, err)
+			}
+			next, err := dec.Token()
+			if err != nil {
+				return fmt.Errorf("invalid FoodData Central JSON %q: %w", path, err)
+			}
+			if next != json.Delim('[') {
+				return fmt.Errorf("invalid FoodData Central JSON %q: expected an array of foods", path)
+			}
+			if err := readJSONFoods(dec, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid FoodData Central JSON %q: expected an object or array", path)
+	}
+}
+
+// readJSONFoods decodes the foods of an array until its closing bracket
+func readJSONFoods(dec *json.Decoder, fn FoodFunc) error {
+	for dec.More() {
+		var food jsonFood
+		if err := dec.Decode(&food); err != nil {
+			return fmt.Errorf("invalid FoodData Central food: %w", err)
+		}
+
+		amounts := map[int]float64{}
+		for _, n := range food.FoodNutrients {
+			if n.Amount != nil && isMappedNutrient(n.Nutrient.ID) {
+				amounts[n.Nutrient.ID] = *n.Amount
+			}
+		}
+
+		if f := newFood(strconv.Itoa(food.FDCID), food.Description, food.category(), amounts); f != nil {
+			if err := fn(f); err != nil {
+				return err
+			}
+		}
+	}
+
+	// closing bracket of the array
+	_, err := dec.Token()
+	return err
+}
@@ -0,0 +1,132 @@
+// Package usda reads USDA FoodData Central (FDC) downloads into reference foods.
+//
+// Both download formats are supported: the JSON files (e.g. FoodData_Central_foundation_food_json_*.json)
+// and the unzipped CSV directories containing food.csv and food_nutrient.csv. The FDC nutrient amounts
+// are per 100 g, the nutrients are mapped to the macros and the nutrient catalog of the service.
+package usda
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dogab/vitalstack/api/pkg/service"
+)
+
+// FDC nutrient IDs (nutrient.id) of the macros, in order of preference
+var (
+	energyIDs  = []int{1008, 2047, 2048} // Energy (kcal), Atwater general and specific factors
+	proteinIDs = []int{1003}
+	carbsIDs   = []int{1005, 1050} // Carbohydrate by difference, by summation
+	fatIDs     = []int{1004}
+	fiberIDs   = []int{1079}
+)
+
+// nutrientIDs maps the IDs of the nutrient catalog to the FDC nutrient IDs, in order of preference.
+// The FDC units match the units of the catalog.
+var nutrientIDs = map[string][]int{
+	"sugar":         {2000, 1063}, // Sugars, total including NLEA, Sugars, Total
+	"saturated_fat": {1258},
+	"sodium":        {1093},
+	"cholesterol":   {1253},
+	"potassium":     {1092},
+	"calcium":       {1087},
+	"iron":          {1089},
+	"vitamin_a":     {1106}, // Vitamin A, RAE
+	"vitamin_c":     {1162},
+	"vitamin_d":     {1114}, // Vitamin D (D2 + D3)
+	"vitamin_b12":   {1178},
+}
+
+// FoodFunc receives every food read from a download. Returning an error stops the reading.
+type FoodFunc func(food *service.Food) error
+
+// Read reads the foods of the JSON file or CSV directory at path. A path to one of the CSV files
+// reads the directory it is in.
+func Read(path string, fn FoodFunc) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		return readCSV(path, fn)
+	case strings.EqualFold(filepath.Ext(path), ".csv"):
+		return readCSV(filepath.Dir(path), fn)
+	case strings.EqualFold(filepath.Ext(path), ".json"):
+		return readJSON(path, fn)
+	default:
+		return fmt.Errorf("unsupported FoodData Central download %q, expected a JSON file or a CSV directory", path)
+	}
+}
+
+// newFood creates the food from the FDC nutrient amounts keyed by FDC nutrient ID.
+// Foods without energy are skipped (nil), they cannot be used to compute macros.
+func newFood(fdcID, description, category string, amounts map[int]float64) *service.Food {
+	calories, ok := amount(amounts, energyIDs)
+	if !ok || description == "" {
+		return nil
+	}
+
+	macros := service.MacroData{
+		Calories: int(math.Round(calories)),
+		Protein:  value(amounts, proteinIDs),
+		Carbs:    value(amounts, carbsIDs),
+		Fat:      value(amounts, fatIDs),
+		Fiber:    value(amounts, fiberIDs),
+	}
+	for _, nutrient := range service.NutrientCatalog {
+		if v, ok := amount(amounts, nutrientIDs[nutrient.ID]); ok {
+			if macros.Nutrients == nil {
+				macros.Nutrients = service.NutrientValues{}
+			}
+			macros.Nutrients[nutrient.ID] = v
+		}
+	}
+
+	return &service.Food{
+		Source:   service.FoodSourceUSDA,
+		SourceID: fdcID,
+		Name:     strings.TrimSpace(description),
+		Category: strings.TrimSpace(category),
+		Per100g:  macros,
+	}
+}
+
+// amount returns the first available amount of the FDC nutrient IDs
+func amount(amounts map[int]float64, ids []int) (float64, bool) {
+	for _, id := range ids {
+		if v, ok := amounts[id]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// value returns the first available amount of the FDC nutrient IDs, missing nutrients count as 0
+func value(amounts map[int]float64, ids []int) float64 {
+	v, _ := amount(amounts, ids)
+	return v
+}
+
+// isMappedNutrient reports whether the FDC nutrient is used for the macros or the nutrient catalog
+func isMappedNutrient(id int) bool {
+	for _, ids := range [][]int{energyIDs, proteinIDs, carbsIDs, fatIDs, fiberIDs} {
+		for _, mapped := range ids {
+			if id == mapped {
+				return true
+			}
+		}
+	}
+	for _, ids := range nutrientIDs {
+		for _, mapped := range ids {
+			if id == mapped {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+// Package prompts embeds the default dotprompt templates of the AI flows.
+// The templates can be replaced at runtime by pointing the prompts.dir config at a directory
+// containing files with the same names.
+package prompts
+
+import "embed"
+
+// FS contains the default .prompt files
+//
+//go:embed *.prompt
+var FS embed.FS
@@ -0,0 +1,5 @@
+-- Record the version of the AI prompt which produced a logged scan, so that the accuracy
+-- of logged meals can be correlated with prompt revisions and A/B tested variants
+ALTER TABLE food_logs ADD COLUMN prompt_version TEXT;
+
+CREATE INDEX food_logs_prompt_version_idx ON food_logs (prompt_version);
@@ -0,0 +1,29 @@
+-- Track nutrients beyond the five macros. The catalog lists the known nutrients and their units,
+-- the amounts of a log or ingredient are stored as a JSON object keyed by nutrient ID, e.g.
+-- {"sodium": 420, "sugar": 12.5}, so that nutrients can be added without schema changes.
+-- The catalog must match service.NutrientCatalog of the API.
+CREATE TABLE nutrients (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    unit TEXT NOT NULL,
+    sort_order INT NOT NULL
+);
+
+INSERT INTO nutrients (id, name, unit, sort_order) VALUES
+    ('sugar', 'Sugar', 'g', 1),
+    ('saturated_fat', 'Saturated fat', 'g', 2),
+    ('sodium', 'Sodium', 'mg', 3),
+    ('cholesterol', 'Cholesterol', 'mg', 4),
+    ('potassium', 'Potassium', 'mg', 5),
+    ('calcium', 'Calcium', 'mg', 6),
+    ('iron', 'Iron', 'mg', 7),
+    ('vitamin_a', 'Vitamin A', 'µg', 8),
+    ('vitamin_c', 'Vitamin C', 'mg', 9),
+    ('vitamin_d', 'Vitamin D', 'µg', 10),
+    ('vitamin_b12', 'Vitamin B12', 'µg', 11);
+
+ALTER TABLE food_logs ADD COLUMN nutrients JSONB;
+ALTER TABLE food_log_ingredients ADD COLUMN nutrients JSONB;
+
+ALTER TABLE nutrients ENABLE ROW LEVEL SECURITY;
+CREATE POLICY "Enable read for nutrients" ON nutrients FOR SELECT USING (true);
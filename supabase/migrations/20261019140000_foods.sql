@@ -0,0 +1,38 @@
+-- Reference food composition data, e.g. imported from USDA FoodData Central with `vitalstack import usda`.
+-- Macros and nutrients are per 100 g of the food, nutrients are keyed by the nutrient IDs of the catalog.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE foods (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    source TEXT NOT NULL,
+    source_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    category TEXT,
+    calories NUMERIC(8, 2) NOT NULL,
+    protein NUMERIC(8, 2) NOT NULL,
+    carbs NUMERIC(8, 2) NOT NULL,
+    fat NUMERIC(8, 2) NOT NULL,
+    fiber NUMERIC(8, 2) NOT NULL,
+    nutrients JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    -- Re-importing a dump updates the foods instead of duplicating them
+    UNIQUE (source, source_id)
+);
+
+CREATE INDEX foods_name_trgm_idx ON foods USING GIN (name gin_trgm_ops);
+
+-- Fuzzy search of the foods by name, best matches first. Foods containing all words of the query
+-- are found even if the name is much longer, e.g. "chicken breast" finds "Chicken, broiler, breast, roasted".
+CREATE FUNCTION search_foods(query TEXT, max_results INT DEFAULT 20)
+RETURNS SETOF foods
+LANGUAGE sql STABLE
+AS $$
+    SELECT *
+    FROM foods
+    WHERE query <% name OR name ILIKE '%' || query || '%'
+    ORDER BY word_similarity(query, name) DESC, length(name)
+    LIMIT max_results;
+$$;
+
+ALTER TABLE foods ENABLE ROW LEVEL SECURITY;
+CREATE POLICY "Enable read for foods" ON foods FOR SELECT USING (true);
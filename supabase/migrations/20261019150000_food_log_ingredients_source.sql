@@ -0,0 +1,4 @@
+-- Record whether the macros of a logged ingredient were estimated by the AI or computed from a matched
+-- food of the food database, and which food it was
+ALTER TABLE food_log_ingredients ADD COLUMN source TEXT;
+ALTER TABLE food_log_ingredients ADD COLUMN food_id BIGINT REFERENCES foods(id) ON DELETE SET NULL;
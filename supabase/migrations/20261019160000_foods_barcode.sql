@@ -0,0 +1,11 @@
+-- Packaged products, e.g. imported from an Open Food Facts dump with `vitalstack import off`, are looked up by the
+-- barcode on the package. The serving size is taken from the label, serving_grams is its weight in grams
+-- (or volume in ml for serving_unit 'ml').
+ALTER TABLE foods
+    ADD COLUMN barcode TEXT,
+    ADD COLUMN brand TEXT,
+    ADD COLUMN serving_size TEXT,
+    ADD COLUMN serving_grams NUMERIC(8, 2),
+    ADD COLUMN serving_unit TEXT;
+
+CREATE INDEX foods_barcode_idx ON foods (barcode) WHERE barcode IS NOT NULL;
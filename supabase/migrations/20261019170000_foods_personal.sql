@@ -0,0 +1,18 @@
+-- Personal foods, e.g. products read from a nutrition label scan, belong to the user who created them.
+-- They are only returned to their user, reference foods have no user.
+ALTER TABLE foods ADD COLUMN user_id UUID REFERENCES public.profiles(id) ON DELETE CASCADE;
+
+CREATE INDEX foods_user_id_idx ON foods (user_id) WHERE user_id IS NOT NULL;
+
+-- The search only covers the reference foods, so that personal foods are neither returned to other users
+-- nor used to ground scans
+CREATE OR REPLACE FUNCTION search_foods(query TEXT, max_results INT DEFAULT 20)
+RETURNS SETOF foods
+LANGUAGE sql STABLE
+AS $$
+    SELECT *
+    FROM foods
+    WHERE user_id IS NULL AND (query <% name OR name ILIKE '%' || query || '%')
+    ORDER BY word_similarity(query, name) DESC, length(name)
+    LIMIT max_results;
+$$;
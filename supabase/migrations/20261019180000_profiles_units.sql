@@ -0,0 +1,3 @@
+-- Preferred measurement system of the user, quantities in API responses are rendered in it
+ALTER TABLE public.profiles
+    ADD COLUMN units TEXT NOT NULL DEFAULT 'metric' CHECK (units IN ('metric', 'imperial'));
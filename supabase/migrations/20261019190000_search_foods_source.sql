@@ -0,0 +1,17 @@
+-- Scans are only grounded against reference foods like USDA, not against branded products whose names
+-- (e.g. "Rolled Oats") rarely describe a generic ingredient. The search can be restricted to one source,
+-- a NULL source searches all of them.
+DROP FUNCTION search_foods(TEXT, INT);
+
+CREATE FUNCTION search_foods(query TEXT, max_results INT DEFAULT 20, food_source TEXT DEFAULT NULL)
+RETURNS SETOF foods
+LANGUAGE sql STABLE
+AS $$
+    SELECT *
+    FROM foods
+    WHERE user_id IS NULL
+        AND (food_source IS NULL OR source = food_source)
+        AND (query <% name OR name ILIKE '%' || query || '%')
+    ORDER BY word_similarity(query, name) DESC, length(name)
+    LIMIT max_results;
+$$;